@@ -0,0 +1,47 @@
+package ctxlog
+
+// RegisterTagAlias makes tags with the key `from` also show up under the key
+// `to` in logged entries. This lets a tag be renamed without having to change
+// everything that produces it at the same time as everything that reads it.
+func RegisterTagAlias(from, to string) {
+	std.RegisterTagAlias(from, to)
+}
+
+// RegisterTagAlias makes tags with the key `from` also show up under the key
+// `to` in entries logged through this Registry.
+func (r *Registry) RegisterTagAlias(from, to string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.aliases == nil {
+		r.aliases = map[string]string{}
+	}
+	r.aliases[from] = to
+}
+
+// applyAliases adds the aliased copies of the entry's tags, right after the
+// originals. Tags which are already in the entry under the alias are left
+// alone. Callers must hold r.mu.
+func (r *Registry) applyAliases(entry LogEntry) LogEntry {
+	if len(r.aliases) == 0 {
+		return entry
+	}
+
+	present := make(map[string]bool, len(entry.Tags))
+	for _, t := range entry.Tags {
+		present[t.K] = true
+	}
+
+	tags := make([]Tag, 0, len(entry.Tags))
+	for _, t := range entry.Tags {
+		tags = append(tags, t)
+
+		if to, ok := r.aliases[t.K]; ok && !present[to] {
+			tags = append(tags, Tag{K: to, V: t.V})
+			present[to] = true
+		}
+	}
+	entry.Tags = tags
+
+	return entry
+}
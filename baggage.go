@@ -0,0 +1,137 @@
+package ctxlog
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// baggageKey is the context key for baggage. Baggage is kept apart from tags,
+// so the two can't collide.
+type baggageKey struct{}
+
+// SetBaggage adds an entry to the context's baggage. Unlike tags, baggage is
+// meant to be passed on to other services, in the W3C `baggage` header. The
+// key must be a token, as the header defines it (letters, digits and
+// "!#$%&'*+-.^_`|~"); if it isn't, the context is returned unchanged. The
+// value can be anything, and is percent-encoded in the header as needed.
+func SetBaggage(ctx context.Context, k, v string) context.Context {
+	if !validBaggageKey(k) {
+		return ctx
+	}
+
+	old := AllBaggage(ctx)
+	bag := make(map[string]string, len(old)+1)
+	for bk, bv := range old {
+		bag[bk] = bv
+	}
+	bag[k] = v
+
+	if lc, ok := ctx.(LoggingContext); ok {
+		lc.Context = context.WithValue(lc.Context, baggageKey{}, bag)
+		return lc
+	}
+
+	return context.WithValue(ctx, baggageKey{}, bag)
+}
+
+// GetBaggage returns the value of the baggage entry `k`, if there is one.
+func GetBaggage(ctx context.Context, k string) (string, bool) {
+	v, ok := AllBaggage(ctx)[k]
+	return v, ok
+}
+
+// AllBaggage returns all of the context's baggage. The map must not be
+// changed.
+func AllBaggage(ctx context.Context) map[string]string {
+	bag, _ := ctx.Value(baggageKey{}).(map[string]string)
+	return bag
+}
+
+// InjectBaggage writes the context's baggage into the `baggage` header of
+// `h`, for sending to another service.
+func InjectBaggage(ctx context.Context, h http.Header) {
+	bag := AllBaggage(ctx)
+	if len(bag) == 0 {
+		return
+	}
+
+	members := make([]string, 0, len(bag))
+	for k, v := range bag {
+		members = append(members, k+"="+escapeBaggageValue(v))
+	}
+	sort.Strings(members)
+
+	h.Set("baggage", strings.Join(members, ","))
+}
+
+// ExtractBaggage adds the entries in the `baggage` header of `h` to the
+// context's baggage, decoding their values. Entries that can't be parsed, or
+// whose keys aren't tokens, are skipped.
+func ExtractBaggage(ctx context.Context, h http.Header) context.Context {
+	for _, header := range h.Values("baggage") {
+		for _, member := range strings.Split(header, ",") {
+			// Properties come after the value, and aren't kept.
+			member = strings.SplitN(member, ";", 2)[0]
+
+			kv := strings.SplitN(member, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+
+			k := strings.TrimSpace(kv[0])
+			v, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+			if !validBaggageKey(k) || err != nil {
+				continue
+			}
+
+			ctx = SetBaggage(ctx, k, v)
+		}
+	}
+
+	return ctx
+}
+
+// validBaggageKey reports whether `k` is a token, which is what the W3C
+// baggage header allows as a key.
+func validBaggageKey(k string) bool {
+	if k == "" {
+		return false
+	}
+
+	for i := 0; i < len(k); i++ {
+		c := k[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// escapeBaggageValue percent-encodes the bytes of `v` that the W3C baggage
+// header doesn't allow in a value, and `%` itself, so that any string can be
+// sent and decoded again.
+func escapeBaggageValue(v string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if c > 0x20 && c < 0x7f && c != '"' && c != ',' && c != ';' && c != '\\' && c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package ctxlog
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestBaggage(t *testing.T) {
+	tests := []struct {
+		name       string
+		k, v       string
+		wantHeader string
+		wantKept   bool
+	}{
+		{"plain", "user", "alice", "user=alice", true},
+		{"space", "name", "Alice Smith", "name=Alice%20Smith", true},
+		{"delimiters", "list", `a,b;c"d\e`, "list=a%2Cb%3Bc%22d%5Ce", true},
+		{"percent", "ratio", "50%", "ratio=50%25", true},
+		{"unicode", "city", "Zürich", "city=Z%C3%BCrich", true},
+		{"allowed punctuation", "expr", "a=b&c:d/e", "expr=a=b&c:d/e", true},
+		{"token key", "my-key.v1", "x", "my-key.v1=x", true},
+		{"empty key", "", "x", "", false},
+		{"key with a space", "my key", "x", "", false},
+		{"key with a comma", "a,b", "x", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := SetBaggage(context.Background(), tt.k, tt.v)
+			if _, ok := GetBaggage(ctx, tt.k); ok != tt.wantKept {
+				t.Fatalf("baggage kept: %v, want %v", ok, tt.wantKept)
+			}
+
+			h := http.Header{}
+			InjectBaggage(ctx, h)
+			if got := h.Get("baggage"); got != tt.wantHeader {
+				t.Errorf("header is %q, want %q", got, tt.wantHeader)
+			}
+			if !tt.wantKept {
+				return
+			}
+
+			v, ok := GetBaggage(ExtractBaggage(context.Background(), h), tt.k)
+			if !ok || v != tt.v {
+				t.Errorf("extracted %q, %v; want %q", v, ok, tt.v)
+			}
+		})
+	}
+}
+
+func TestExtractBaggageSkipsInvalidMembers(t *testing.T) {
+	h := http.Header{}
+	h.Set("baggage", "good=1, bad key=2,noequals, prop=3;ttl=10")
+
+	got := AllBaggage(ExtractBaggage(context.Background(), h))
+	want := map[string]string{"good": "1", "prop": "3"}
+	if len(got) != len(want) {
+		t.Fatalf("extracted %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s is %q, want %q", k, got[k], v)
+		}
+	}
+}
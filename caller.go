@@ -0,0 +1,30 @@
+package ctxlog
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+)
+
+// WithCaller adds the location of a calling function to the context, as the
+// `caller_file`, `caller_line` and `caller_func` tags. With a `skip` of 0
+// that's the function calling WithCaller, 1 is the function that called it,
+// and so on. Helpers for error paths can use it to log where they were
+// called from, rather than where they are.
+func WithCaller(ctx context.Context, skip int) context.Context {
+	pc, file, line, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ctx
+	}
+
+	fn := "unknown"
+	if f := runtime.FuncForPC(pc); f != nil {
+		fn = f.Name()
+	}
+
+	return registryFor(ctx).WithAll(ctx,
+		Tag{K: "caller_file", V: filepath.Base(file), Override: true},
+		Tag{K: "caller_line", V: line, Override: true},
+		Tag{K: "caller_func", V: fn, Override: true},
+	)
+}
@@ -0,0 +1,87 @@
+package ctxlog
+
+import (
+	"context"
+	"time"
+)
+
+// OnCancel logs `msg` once `ctx` is done, with the context's tags, any extra
+// `tags`, and the reason it was cancelled as `cancel_cause`.
+func OnCancel(ctx context.Context, msg string, tags ...Tag) {
+	registryFor(ctx).OnCancel(ctx, msg, tags...)
+}
+
+// OnCancel logs `msg` through this Registry once `ctx` is done, with the
+// context's tags, any extra `tags`, and the reason it was cancelled as
+// `cancel_cause`.
+//
+// No goroutine waits for `ctx`: the hook is registered with
+// context.AfterFunc, so it's kept only as long as `ctx` is, and goes away
+// with it if it's never cancelled.
+func (r *Registry) OnCancel(ctx context.Context, msg string, tags ...Tag) {
+	context.AfterFunc(ctx, func() {
+		logCtx := r.WithAll(ctx, tags...)
+		logCtx = r.withAll(logCtx, Tag{K: "cancel_cause", V: context.Cause(ctx), Override: true})
+		r.Infof(logCtx, "%s", msg)
+	})
+}
+
+// OnDone calls `fn` in its own goroutine once `ctx` is done. If `fn` panics,
+// the panic is logged with the tags `ctx` had when OnDone was called. The
+// returned function stops `fn` from being called, like context.AfterFunc.
+func OnDone(ctx context.Context, fn func()) (stop func() bool) {
+	return registryFor(ctx).OnDone(ctx, fn)
+}
+
+// OnDone calls `fn` once `ctx` is done, logging any panic from it through
+// this Registry.
+func (r *Registry) OnDone(ctx context.Context, fn func()) (stop func() bool) {
+	// Take the tags now, since `ctx` may have been replaced by the time it's
+	// done, and don't hold on to its cancellation.
+	logCtx := r.Clone(ctx)
+
+	return context.AfterFunc(ctx, func() {
+		defer func() {
+			if p := recover(); p != nil {
+				r.Errorf(logCtx, "OnDone callback panicked: %v", p)
+			}
+		}()
+
+		fn()
+	})
+}
+
+// WithDeadlineWarning logs `msg` as a warning when `ctx` is `before` away
+// from its deadline, with `deadline_at` and `time_remaining_ms` tags. Nothing
+// is logged if the context is done first, or has no deadline.
+func WithDeadlineWarning(ctx context.Context, before time.Duration, msg string) context.Context {
+	return registryFor(ctx).WithDeadlineWarning(ctx, before, msg)
+}
+
+// WithDeadlineWarning logs `msg` as a warning through this Registry when
+// `ctx` is `before` away from its deadline.
+func (r *Registry) WithDeadlineWarning(ctx context.Context, before time.Duration, msg string) context.Context {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx
+	}
+
+	go func() {
+		timer := time.NewTimer(time.Until(deadline.Add(-before)))
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		logCtx := r.withAll(ctx,
+			Tag{K: "deadline_at", V: deadline, Override: true},
+			Tag{K: "time_remaining_ms", V: time.Until(deadline).Milliseconds(), Override: true},
+		)
+		r.Warnf(logCtx, "%s", msg)
+	}()
+
+	return ctx
+}
@@ -0,0 +1,61 @@
+package ctxlog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOnCancel(t *testing.T) {
+	stopped := errors.New("stopped")
+
+	tests := []struct {
+		name      string
+		cancel    func(cancel context.CancelCauseFunc)
+		wantCause error
+	}{
+		{"cancelled", func(cancel context.CancelCauseFunc) { cancel(nil) }, context.Canceled},
+		{"with a cause", func(cancel context.CancelCauseFunc) { cancel(stopped) }, stopped},
+		{"never cancelled", func(context.CancelCauseFunc) {}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, sink := newRecordingRegistry()
+			ctx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+			ctx = r.With(ctx, "request", 1)
+
+			r.OnCancel(ctx, "gave up", Tag{K: "extra", V: true})
+			tt.cancel(cancel)
+
+			var entries []LogEntry
+			for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); {
+				if entries = sink.Entries(); len(entries) > 0 || tt.wantCause == nil {
+					break
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			if tt.wantCause == nil {
+				if len(entries) != 0 {
+					t.Errorf("logged %v, want nothing", entries)
+				}
+				return
+			}
+
+			if len(entries) != 1 || entries[0].Message != "gave up" {
+				t.Fatalf("logged %v, want one entry", entries)
+			}
+			e := entries[0]
+			for _, k := range []string{"request", "extra"} {
+				if _, ok := e.tag(k); !ok {
+					t.Errorf("entry has no %q tag", k)
+				}
+			}
+			if cause, _ := e.tag("cancel_cause"); cause != tt.wantCause {
+				t.Errorf("entry has cancel_cause %v, want %v", cause, tt.wantCause)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package ctxlog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CircuitState is the state of a CircuitBreakerSink.
+type CircuitState int
+
+// The states a CircuitBreakerSink can be in.
+const (
+	// Entries are being sent to the inner sink.
+	CircuitClosed CircuitState = iota
+	// The inner sink has been failing, so entries go to the console instead.
+	CircuitOpen
+	// The timeout has passed, and the next entry will be sent to the inner
+	// sink to see if it has recovered. Other entries go to the console until
+	// it has.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerSink stops sending entries to a sink that keeps failing, so
+// that a broken remote sink doesn't slow down every logging call. While the
+// circuit is open, entries go to the console of the Registry the sink was
+// added to instead.
+type CircuitBreakerSink struct {
+	inner     Sink
+	threshold int
+	timeout   time.Duration
+
+	// The Registry whose console entries fall back to.
+	r atomic.Pointer[Registry]
+
+	// Set while an entry is being tried on the inner sink in the half-open
+	// state, so that only one is.
+	probing int32
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreakerSink wraps `inner` so that after `threshold` errors in a
+// row, entries are sent to the console instead. After `timeout`, the next
+// entry is tried on `inner` again, and the circuit closes if it succeeds. Use
+// a type assertion to *CircuitBreakerSink to check its State.
+func NewCircuitBreakerSink(inner Sink, threshold int, timeout time.Duration) Sink {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &CircuitBreakerSink{
+		inner:     inner,
+		threshold: threshold,
+		timeout:   timeout,
+	}
+}
+
+// attach passes the Registry on to the inner sink, as well as keeping it for
+// its console.
+func (cb *CircuitBreakerSink) attach(r *Registry) {
+	cb.r.Store(r)
+	attach(cb.inner, r)
+}
+
+// console logs the entry to the console of the Registry the sink was added
+// to, or the default one if it hasn't been.
+func (cb *CircuitBreakerSink) console(entry LogEntry) error {
+	r := cb.r.Load()
+	if r == nil {
+		r = std
+	}
+
+	return r.console.Log(entry)
+}
+
+// State returns the current state of the circuit.
+func (cb *CircuitBreakerSink) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.current()
+}
+
+// current moves an open circuit to half-open once the timeout has passed.
+// Callers must hold cb.mu.
+func (cb *CircuitBreakerSink) current() CircuitState {
+	if cb.state == CircuitOpen && time.Since(cb.openedAt) >= cb.timeout {
+		cb.state = CircuitHalfOpen
+	}
+
+	return cb.state
+}
+
+// Log sends the entry to the inner sink, or to the console if the circuit is
+// open.
+func (cb *CircuitBreakerSink) Log(entry LogEntry) error {
+	cb.mu.Lock()
+	state := cb.current()
+	cb.mu.Unlock()
+
+	switch state {
+	case CircuitOpen:
+		return cb.console(entry)
+	case CircuitHalfOpen:
+		// Only one entry gets to find out whether the inner sink has
+		// recovered; the rest carry on as if the circuit were open.
+		if !atomic.CompareAndSwapInt32(&cb.probing, 0, 1) {
+			return cb.console(entry)
+		}
+		defer atomic.StoreInt32(&cb.probing, 0)
+	}
+
+	err := cb.inner.Log(entry)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if err == nil {
+		cb.failures = 0
+		cb.state = CircuitClosed
+		return nil
+	}
+
+	cb.failures++
+	if cb.state == CircuitHalfOpen || cb.failures >= cb.threshold {
+		cb.state = CircuitOpen
+		cb.openedAt = time.Now()
+	}
+
+	return err
+}
+
+// Close closes the inner sink, or flushes it if it can't be closed.
+func (cb *CircuitBreakerSink) Close() error {
+	return closeSink(cb.inner)
+}
+
+// Flush flushes the inner sink, if it can be flushed.
+func (cb *CircuitBreakerSink) Flush() error {
+	return flushSink(cb.inner)
+}
@@ -0,0 +1,131 @@
+package ctxlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingSink fails while `fail` is set, and otherwise keeps the entries
+// it's given. If `block` is set, Log waits for it to be closed first.
+type failingSink struct {
+	recordingSink
+	fail  bool
+	block chan struct{}
+}
+
+func (fs *failingSink) Log(entry LogEntry) error {
+	if fs.block != nil {
+		<-fs.block
+	}
+	if fs.fail {
+		return errors.New("sink is down")
+	}
+
+	return fs.recordingSink.Log(entry)
+}
+
+// newCircuitBreakerRegistry creates a Registry that logs through a circuit
+// breaker around `inner`. Only what the breaker sends to the console is
+// written to the returned buffer.
+func newCircuitBreakerRegistry(inner Sink, timeout time.Duration) (*Registry, *CircuitBreakerSink, *bytes.Buffer) {
+	r := NewRegistry()
+	r.RemoveSink("console")
+	console := &bytes.Buffer{}
+	r.console.out = console
+
+	cb := NewCircuitBreakerSink(inner, 2, timeout).(*CircuitBreakerSink)
+	r.UseSink("remote", cb)
+	return r, cb, console
+}
+
+func TestCircuitBreakerSink(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int
+		wait      time.Duration
+		recovered bool
+		wantState CircuitState
+		wantInner int
+	}{
+		{"healthy", 0, 0, true, CircuitClosed, 1},
+		{"below threshold", 1, 0, false, CircuitClosed, 0},
+		{"open", 2, 0, false, CircuitOpen, 0},
+		{"half-open", 2, 20 * time.Millisecond, false, CircuitHalfOpen, 0},
+		{"still open", 2, 0, true, CircuitOpen, 0},
+		{"recovered", 2, 20 * time.Millisecond, true, CircuitClosed, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &failingSink{fail: true}
+			r, cb, console := newCircuitBreakerRegistry(inner, 10*time.Millisecond)
+			ctx := context.Background()
+
+			for i := 0; i < tt.failures; i++ {
+				r.Infof(ctx, "failed")
+			}
+			time.Sleep(tt.wait)
+
+			if tt.recovered {
+				inner.fail = false
+				r.Infof(ctx, "recovered")
+			}
+
+			if got := cb.State(); got != tt.wantState {
+				t.Errorf("circuit is %v, want %v", got, tt.wantState)
+			}
+			if got := len(inner.Entries()); got != tt.wantInner {
+				t.Errorf("inner sink has %d entries, want %d", got, tt.wantInner)
+			}
+
+			wantConsole := tt.wantState == CircuitOpen && tt.recovered
+			if got := strings.Contains(console.String(), "recovered"); got != wantConsole {
+				t.Errorf("entry sent to the console: %v, want %v", got, wantConsole)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerSinkProbesOnce(t *testing.T) {
+	inner := &failingSink{fail: true}
+	r, cb, console := newCircuitBreakerRegistry(inner, time.Millisecond)
+	ctx := context.Background()
+
+	r.Infof(ctx, "failed")
+	r.Infof(ctx, "failed")
+	time.Sleep(5 * time.Millisecond)
+	if got := cb.State(); got != CircuitHalfOpen {
+		t.Fatalf("circuit is %v, want %v", got, CircuitHalfOpen)
+	}
+
+	// Hold the first entry in the inner sink, so that the second arrives
+	// while it's still being tried.
+	inner.fail = false
+	inner.block = make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		r.Infof(ctx, "probe")
+		close(done)
+	}()
+
+	for atomic.LoadInt32(&cb.probing) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	r.Infof(ctx, "second")
+	close(inner.block)
+	<-done
+
+	if got := cb.State(); got != CircuitClosed {
+		t.Errorf("circuit is %v, want %v", got, CircuitClosed)
+	}
+	if entries := inner.Entries(); len(entries) != 1 || entries[0].Message != "probe" {
+		t.Errorf("inner sink has %v, want only the probe", entries)
+	}
+	if !strings.Contains(console.String(), "second") {
+		t.Errorf("second entry wasn't sent to the console: %q", console.String())
+	}
+}
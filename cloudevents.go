@@ -0,0 +1,44 @@
+package ctxlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CloudEventsFormatter wraps each entry in a CloudEvents 1.0 JSON envelope,
+// so that entries can be published to any CloudEvents-compatible event bus.
+type CloudEventsFormatter struct {
+	// The service named in each event's `source`. If it's empty, the
+	// entry's `service` tag is used, if it has one.
+	ServiceName string
+}
+
+// Format returns the entry as a CloudEvent. Each event gets a random UUID as
+// its ID, since every entry in a span shares the span's ID, and consumers use
+// the ID to drop duplicate events.
+func (f CloudEventsFormatter) Format(entry LogEntry) ([]byte, error) {
+	service := f.ServiceName
+	if service == "" {
+		if s, ok := entry.tag("service"); ok {
+			service = fmt.Sprint(s)
+		}
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"specversion":     "1.0",
+		"type":            "com.ctxlog.log",
+		"source":          service + "/" + entry.InstanceID,
+		"id":              id.String(),
+		"time":            entry.Time.Format(time.RFC3339Nano),
+		"datacontenttype": "application/json",
+		"data":            entry.ToJSON(),
+	})
+}
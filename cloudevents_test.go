@@ -0,0 +1,53 @@
+package ctxlog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCloudEventsFormatter(t *testing.T) {
+	tests := []struct {
+		name       string
+		formatter  CloudEventsFormatter
+		tags       []Tag
+		wantSource string
+	}{
+		{"service name", CloudEventsFormatter{ServiceName: "api"}, nil, "api/instance"},
+		{"service tag", CloudEventsFormatter{}, []Tag{{K: "service", V: "worker"}}, "worker/instance"},
+		{"in a span", CloudEventsFormatter{ServiceName: "api"}, []Tag{{K: "span_id", V: "abc"}}, "api/instance"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := LogEntry{Time: time.Now(), Message: "hello", Tags: tt.tags, InstanceID: "instance"}
+
+			ids := map[string]bool{}
+			for i := 0; i < 2; i++ {
+				out, err := tt.formatter.Format(entry)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var event map[string]interface{}
+				if err := json.Unmarshal(out, &event); err != nil {
+					t.Fatal(err)
+				}
+				if event["source"] != tt.wantSource {
+					t.Errorf("source is %v, want %q", event["source"], tt.wantSource)
+				}
+
+				id, _ := event["id"].(string)
+				if _, err := uuid.Parse(id); err != nil {
+					t.Errorf("id %q isn't a UUID", id)
+				}
+				ids[id] = true
+			}
+
+			if len(ids) != 2 {
+				t.Errorf("events got the same id, want one per event")
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// ConditionalLogger only logs if the condition it was created with held for
+// the context at the time. The zero value never logs.
+type ConditionalLogger struct {
+	r   *Registry
+	ctx context.Context
+	ok  bool
+}
+
+// IfHasTag returns a logger which only logs if `ctx` has the tag `key`.
+func IfHasTag(ctx context.Context, key string) *ConditionalLogger {
+	return registryFor(ctx).IfHasTag(ctx, key)
+}
+
+// IfTagEquals returns a logger which only logs if one of the values of the
+// tag `key` in `ctx` is equal to `value`.
+func IfTagEquals(ctx context.Context, key string, value interface{}) *ConditionalLogger {
+	return registryFor(ctx).IfTagEquals(ctx, key, value)
+}
+
+// IfTagMatches returns a logger which only logs if one of the values of the
+// tag `key` in `ctx`, once formatted, matches `re`.
+func IfTagMatches(ctx context.Context, key string, re *regexp.Regexp) *ConditionalLogger {
+	return registryFor(ctx).IfTagMatches(ctx, key, re)
+}
+
+// IfHasTag returns a logger which only logs through this Registry if `ctx`
+// has the tag `key`.
+func (r *Registry) IfHasTag(ctx context.Context, key string) *ConditionalLogger {
+	_, ok := tagValues(ctx, key)
+	return &ConditionalLogger{r: r, ctx: ctx, ok: ok}
+}
+
+// IfTagEquals returns a logger which only logs through this Registry if one
+// of the values of the tag `key` in `ctx` is equal to `value`.
+func (r *Registry) IfTagEquals(ctx context.Context, key string, value interface{}) *ConditionalLogger {
+	vals, _ := tagValues(ctx, key)
+	for _, v := range vals {
+		if reflect.DeepEqual(v, value) {
+			return &ConditionalLogger{r: r, ctx: ctx, ok: true}
+		}
+	}
+
+	return &ConditionalLogger{r: r, ctx: ctx}
+}
+
+// IfTagMatches returns a logger which only logs through this Registry if one
+// of the values of the tag `key` in `ctx`, once formatted, matches `re`.
+func (r *Registry) IfTagMatches(ctx context.Context, key string, re *regexp.Regexp) *ConditionalLogger {
+	vals, _ := tagValues(ctx, key)
+	for _, v := range vals {
+		if re != nil && re.MatchString(fmt.Sprint(v)) {
+			return &ConditionalLogger{r: r, ctx: ctx, ok: true}
+		}
+	}
+
+	return &ConditionalLogger{r: r, ctx: ctx}
+}
+
+// Infof prints an informational string if the condition held.
+func (cl *ConditionalLogger) Infof(msg string, args ...interface{}) {
+	if cl == nil || !cl.ok {
+		return
+	}
+
+	cl.r.Infof(cl.ctx, msg, args...)
+}
+
+// Debugf prints debug info if the condition held and debug logging is enabled.
+func (cl *ConditionalLogger) Debugf(msg string, args ...interface{}) {
+	if cl == nil || !cl.ok {
+		return
+	}
+
+	cl.r.Debugf(cl.ctx, msg, args...)
+}
+
+// Warnf prints a warning if the condition held.
+func (cl *ConditionalLogger) Warnf(msg string, args ...interface{}) {
+	if cl == nil || !cl.ok {
+		return
+	}
+
+	cl.r.Warnf(cl.ctx, msg, args...)
+}
+
+// Errorf prints an error log if the condition held.
+func (cl *ConditionalLogger) Errorf(msg string, args ...interface{}) {
+	if cl == nil || !cl.ok {
+		return
+	}
+
+	cl.r.Errorf(cl.ctx, msg, args...)
+}
+
+// tagValues returns all of the values of the tag `key` in `ctx`, if it is a
+// LoggingContext that has one.
+func tagValues(ctx context.Context, key string) ([]interface{}, bool) {
+	switch ctx.(type) {
+	case LoggingContext:
+		lc := ctx.(LoggingContext)
+		return lc.tags.get(key)
+	default:
+		return nil, false
+	}
+}
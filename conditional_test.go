@@ -0,0 +1,56 @@
+package ctxlog
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+func TestConditionalLogger(t *testing.T) {
+	tests := []struct {
+		name string
+		cond func(r *Registry, ctx context.Context) *ConditionalLogger
+		want bool
+	}{
+		{"has tag", func(r *Registry, ctx context.Context) *ConditionalLogger {
+			return r.IfHasTag(ctx, "user")
+		}, true},
+		{"missing tag", func(r *Registry, ctx context.Context) *ConditionalLogger {
+			return r.IfHasTag(ctx, "order")
+		}, false},
+		{"equal", func(r *Registry, ctx context.Context) *ConditionalLogger {
+			return r.IfTagEquals(ctx, "user", "alice")
+		}, true},
+		{"not equal", func(r *Registry, ctx context.Context) *ConditionalLogger {
+			return r.IfTagEquals(ctx, "user", "bob")
+		}, false},
+		{"matches", func(r *Registry, ctx context.Context) *ConditionalLogger {
+			return r.IfTagMatches(ctx, "user", regexp.MustCompile("^al"))
+		}, true},
+		{"doesn't match", func(r *Registry, ctx context.Context) *ConditionalLogger {
+			return r.IfTagMatches(ctx, "user", regexp.MustCompile("^bo"))
+		}, false},
+		{"nil", func(*Registry, context.Context) *ConditionalLogger {
+			return nil
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, sink := newRecordingRegistry()
+			cl := tt.cond(r, r.With(context.Background(), "user", "alice"))
+
+			cl.Debugf("debug")
+			cl.Infof("info")
+			cl.Warnf("warn")
+			cl.Errorf("error")
+
+			want := 0
+			if tt.want {
+				want = 4
+			}
+			if got := len(sink.Entries()); got != want {
+				t.Errorf("logged %d entries, want %d", got, want)
+			}
+		})
+	}
+}
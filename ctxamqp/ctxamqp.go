@@ -0,0 +1,40 @@
+// Package ctxamqp carries ctxlog contexts across RabbitMQ, in AMQP message
+// headers, so that consumers' logs can be tied back to whoever published the
+// message.
+package ctxamqp
+
+import (
+	"context"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// headerPrefix keeps our headers apart from any others on the message.
+const headerPrefix = "ctxlog-"
+
+// InjectAMQP adds the context's trace ID, span ID, instance ID and any tags
+// marked with ctxlog.Propagate to `headers`.
+func InjectAMQP(ctx context.Context, headers amqp.Table) {
+	for k, v := range ctxlog.PropagationFields(ctx) {
+		headers[headerPrefix+k] = v
+	}
+}
+
+// ExtractAMQP returns `ctx` with the fields that InjectAMQP added to
+// `headers`.
+func ExtractAMQP(ctx context.Context, headers amqp.Table) context.Context {
+	fields := map[string]string{}
+	for k, v := range headers {
+		s, ok := v.(string)
+		if !ok || !strings.HasPrefix(k, headerPrefix) {
+			continue
+		}
+
+		fields[strings.TrimPrefix(k, headerPrefix)] = s
+	}
+
+	return ctxlog.FromPropagation(ctx, fields)
+}
@@ -0,0 +1,24 @@
+module github.com/silversupreme/ctxlog/ctxamqp
+
+go 1.21
+
+require (
+	github.com/rabbitmq/amqp091-go v1.9.0
+	github.com/silversupreme/ctxlog v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/fatih/color v1.7.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/uuid v1.3.1 // indirect
+	github.com/mattn/go-colorable v0.1.2 // indirect
+	github.com/mattn/go-isatty v0.0.8 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/oauth2 v0.16.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	google.golang.org/appengine v1.6.8 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+)
+
+replace github.com/silversupreme/ctxlog => ..
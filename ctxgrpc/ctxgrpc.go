@@ -0,0 +1,35 @@
+// Package ctxgrpc adds tags describing gRPC calls to ctxlog contexts.
+package ctxgrpc
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// WithGRPCMethod adds the `grpc_service` and `grpc_method` tags from a full
+// method name like "/package.Service/Method", as given to interceptors.
+// Names that aren't in that form are added as the method, with no service.
+func WithGRPCMethod(ctx context.Context, fullMethod string) context.Context {
+	service, method := "", strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(method, "/"); i >= 0 {
+		service, method = method[:i], method[i+1:]
+	}
+
+	return ctxlog.WithAll(ctx,
+		ctxlog.Tag{K: "grpc_service", V: service, Override: true},
+		ctxlog.Tag{K: "grpc_method", V: method, Override: true},
+	)
+}
+
+// WithGRPCStatus adds the `grpc_status_code` and `grpc_status_text` tags for
+// the result of a call, like 5 and "NotFound".
+func WithGRPCStatus(ctx context.Context, code codes.Code) context.Context {
+	return ctxlog.WithAll(ctx,
+		ctxlog.Tag{K: "grpc_status_code", V: uint32(code), Override: true},
+		ctxlog.Tag{K: "grpc_status_text", V: code.String(), Override: true},
+	)
+}
@@ -0,0 +1,45 @@
+// Package ctxkafka carries ctxlog contexts across Kafka, in message headers,
+// so that consumers' logs can be tied back to whoever produced the message.
+package ctxkafka
+
+import (
+	"context"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// headerPrefix keeps our headers apart from any others on the message.
+const headerPrefix = "ctxlog-"
+
+// InjectKafkaHeaders adds the context's trace ID, span ID, instance ID and
+// any tags marked with ctxlog.Propagate to `headers`, replacing any that are
+// already there.
+func InjectKafkaHeaders(ctx context.Context, headers *[]kafka.Header) {
+	kept := (*headers)[:0:0]
+	for _, h := range *headers {
+		if !strings.HasPrefix(h.Key, headerPrefix) {
+			kept = append(kept, h)
+		}
+	}
+
+	for k, v := range ctxlog.PropagationFields(ctx) {
+		kept = append(kept, kafka.Header{Key: headerPrefix + k, Value: []byte(v)})
+	}
+	*headers = kept
+}
+
+// ExtractKafkaHeaders returns `ctx` with the fields that InjectKafkaHeaders
+// added to `headers`.
+func ExtractKafkaHeaders(ctx context.Context, headers []kafka.Header) context.Context {
+	fields := map[string]string{}
+	for _, h := range headers {
+		if strings.HasPrefix(h.Key, headerPrefix) {
+			fields[strings.TrimPrefix(h.Key, headerPrefix)] = string(h.Value)
+		}
+	}
+
+	return ctxlog.FromPropagation(ctx, fields)
+}
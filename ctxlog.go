@@ -3,11 +3,11 @@ package ctxlog
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
-	"time"
+	"strings"
 
 	"github.com/fatih/color"
-	"github.com/google/uuid"
 )
 
 // Tag represents a piece of structured information that should be
@@ -26,279 +26,179 @@ var (
 
 	infoC  *color.Color = color.New(color.FgCyan, color.Bold)
 	debugC *color.Color = color.New(color.FgMagenta, color.Bold)
+	warnC  *color.Color = color.New(color.FgYellow, color.Bold)
 	errC   *color.Color = color.New(color.FgRed, color.Bold)
 	fatalC *color.Color = color.New(color.FgBlack, color.BgRed, color.Bold)
 
-	// The logging context will always include a random UUID which is tagged
-	// to uniquely identify this particular version/invocation of this program.
-	// Allows us to see when restarts happen/induce changes in behaviour.
-	globalUUID uuid.UUID
+	// The same hues without the weight, for tag values.
+	infoValC  *color.Color = color.New(color.FgCyan)
+	debugValC *color.Color = color.New(color.FgMagenta)
+	warnValC  *color.Color = color.New(color.FgYellow)
+	errValC   *color.Color = color.New(color.FgRed)
+
+	// The package-level functions all delegate to this registry.
+	std *Registry
 )
 
 func init() {
 	// Disable colorized log output if we've been requested to do that.
-	if noColor := os.Getenv("DISABLE_COLOR_OUTPUT"); noColor == "1" {
-		infoC.DisableColor()
-		debugC.DisableColor()
-		errC.DisableColor()
-		fatalC.DisableColor()
-	} else {
-		// Always force color otherwise.
-		infoC.EnableColor()
-		debugC.EnableColor()
-		errC.EnableColor()
-		fatalC.EnableColor()
+	noColor := os.Getenv("DISABLE_COLOR_OUTPUT") == "1"
+	for _, c := range []*color.Color{infoC, debugC, warnC, errC, fatalC, infoValC, debugValC, warnValC, errValC} {
+		if noColor {
+			c.DisableColor()
+		} else {
+			// Always force color otherwise.
+			c.EnableColor()
+		}
 	}
 
-	id, err := uuid.NewRandom()
-	if err != nil {
-		globalUUID = uuid.Nil
-		console.Log(context.Background(), errC, "ERROR",
-			"Could not create a unique ID for this application: %v", err)
-	} else {
-		globalUUID = id
-	}
+	std = newRegistry(debug)
+	std.setBuildInfo(Version, Commit, BuildDate)
 }
 
 // LoggingContext allows structured logging information (in the form of "tags")
 // to be carried across API boundaries in an application.
+//
+// LoggingContexts aren't pooled. Each one is shared by every context made
+// from it, and any of them can be kept by a goroutine that outlives the
+// request, so there's no point at which one is known to be unused. Instead,
+// With only adds to the tags it's given rather than copying them, so it
+// makes the same three small allocations however many tags the context
+// already has (see the BenchmarkWith benchmarks).
 type LoggingContext struct {
 	context.Context
 
-	tags  map[string][]interface{}
-	order []string
+	tags *tagSet
+
+	// The namespace that keys added with With are put under, if any.
+	ns string
 }
 
 // ToJSON returns a representation of the context's current data suitable for
 // logging to an external database.
 func (c LoggingContext) ToJSON() map[string]interface{} {
+	r := registryFor(c)
 	ret := map[string]interface{}{
-		"instance_id": globalUUID.String(),
+		"instance_id": r.id.String(),
 	}
 
-	for k, v := range c.tags {
-		// Special-case single-item lists, to just use the value. Helps with
-		// querying in the future.
-		if len(v) == 1 {
-			ret[k] = v[0]
-		} else {
-			ret[k] = v
-		}
+	// Single-item lists are special-cased to just use the value. Helps with
+	// querying in the future.
+	for _, t := range tagsOf(c) {
+		ret[t.K] = t.V
 	}
 
 	return ret
 }
 
+// String returns a compact representation of the context's tags, like
+// `LoggingContext{user_id=123, request_id=abc}`.
+func (c LoggingContext) String() string {
+	var parts []string
+	for _, t := range tagsOf(c) {
+		parts = append(parts, fmt.Sprintf("%s=%v", t.K, t.V))
+	}
+
+	return "LoggingContext{" + strings.Join(parts, ", ") + "}"
+}
+
 // With adds a tag to the context, which is carried into subsequent logging calls.
 func With(ctx context.Context, k string, v interface{}) context.Context {
-	return WithAll(ctx, Tag{K: k, V: v})
+	return registryFor(ctx).With(ctx, k, v)
 }
 
 // WithAll adds multiple tags at once to a context, which avoids a ton of
 // GC churn when you know you have multiple things to add to a logging
 // statement.
 func WithAll(ctx context.Context, tags ...Tag) context.Context {
-	ret := LoggingContext{
-		tags:  map[string][]interface{}{},
-		order: []string{},
-	}
-
-	switch ctx.(type) {
-	case LoggingContext:
-		lc := ctx.(LoggingContext)
-		ret.tags = make(map[string][]interface{}, (len(lc.tags) + 1))
-		ret.order = make([]string, len(lc.order))
-		ret.Context = lc.Context
-
-		// This sucks, in a lot of ways, but it's necessary to allow us to properly
-		// log with ctxlog without downstream functions overwriting or adding to
-		// the tag set for a given context.
-		for i, x := range lc.order {
-			ret.order[i] = x
-		}
-
-		for i, x := range lc.tags {
-			ret.tags[i] = x
-		}
-	default:
-		ret.Context = ctx
-		ret.tags = make(map[string][]interface{}, 1)
-	}
-
-	// Add all the tags.
-	for _, x := range tags {
-		// Don't print multiple times.
-		if _, exists := ret.tags[x.K]; !exists {
-			ret.order = append(ret.order, x.K)
-		}
-
-		if x.Override {
-			ret.tags[x.K] = []interface{}{x.V}
-		} else {
-			ret.tags[x.K] = append(ret.tags[x.K], x.V)
-		}
-	}
-
-	return ret
+	return registryFor(ctx).WithAll(ctx, tags...)
 }
 
 // WithValue is a hack to support adding WithValue to contexts without losing
 // logging information.
 func WithValue(parent context.Context, k string, v interface{}) context.Context {
-	switch parent.(type) {
-	case LoggingContext:
-		lc := parent.(LoggingContext)
-		lc.Context = context.WithValue(lc.Context, k, v)
-		return lc
-	default:
-		ctx := context.WithValue(parent, k, v)
-		return LoggingContext{Context: ctx, tags: map[string][]interface{}{}}
-	}
+	return registryFor(parent).WithValue(parent, k, v)
 }
 
-// Clone creates a copy of `source` with all of the tags intact.
-// TODO: Make a version of this that takes in a context and copies over.
-func Clone(source context.Context) context.Context {
-	switch source.(type) {
-	case LoggingContext:
-		lc := source.(LoggingContext)
-		ret := LoggingContext{
-			Context: context.Background(),
-			tags:    make(map[string][]interface{}, len(lc.tags)),
-			order:   make([]string, len(lc.order)),
-		}
-
-		// This sucks, in a lot of ways, but it's necessary to allow us to properly
-		// log with ctxlog without downstream functions overwriting or adding to
-		// the tag set for a given context.
-		for i, x := range lc.order {
-			ret.order[i] = x
-		}
-
-		for i, x := range lc.tags {
-			ret.tags[i] = x
-		}
+// ContextValue is like context.WithValue, but always returns a
+// LoggingContext, so that the value and any tags on `parent` are both kept.
+// Unlike WithValue, the key can be of any comparable type, like an
+// unexported struct type, which is the usual way to avoid collisions.
+func ContextValue(parent context.Context, key, val interface{}) context.Context {
+	return registryFor(parent).ContextValue(parent, key, val)
+}
 
-		return ret
-	default:
-		return LoggingContext{
-			Context: context.Background(),
-			tags:    map[string][]interface{}{},
-		}
-	}
+// Clone creates a copy of `source` with all of the tags intact, and the
+// Registry it carries, if any.
+func Clone(source context.Context) context.Context {
+	return registryFor(source).Clone(source)
 }
 
-func logf(ctx context.Context, c *color.Color, levelname string, msg string, args ...interface{}) {
-	for name, sink := range sinks {
-		if err := sink.Log(ctx, c, levelname, msg, args...); err != nil {
-			console.Log(ctx, errC, "ERROR", "Could not process log sink '%s': %v", name, err)
+// WithContext returns a context that keeps the deadline, cancellation and
+// values of `parent`, but also carries the tags from `donor`. If `donor`
+// carries a Registry and `parent` doesn't, the result carries it too.
+func WithContext(parent, donor context.Context) context.Context {
+	if _, ok := LoggerFromContext(parent); !ok {
+		if l, ok := LoggerFromContext(donor); ok {
+			parent = ContextWithLogger(parent, l)
 		}
 	}
+
+	return registryFor(parent).WithContext(parent, donor)
 }
 
 // Infof prints an informational string to the console.
 func Infof(ctx context.Context, msg string, args ...interface{}) {
-	logf(ctx, infoC, "INFO", msg, args...)
+	registryFor(ctx).Infof(ctx, msg, args...)
 }
 
 // Debugf prints debug info if that has been enabled in the program.
 func Debugf(ctx context.Context, msg string, args ...interface{}) {
-	if !*debug {
-		return
-	}
+	registryFor(ctx).Debugf(ctx, msg, args...)
+}
 
-	logf(ctx, debugC, "DEBUG", msg, args...)
+// Warnf prints a warning to the console.
+func Warnf(ctx context.Context, msg string, args ...interface{}) {
+	registryFor(ctx).Warnf(ctx, msg, args...)
 }
 
 // Errorf prints an error log to the console.
 func Errorf(ctx context.Context, msg string, args ...interface{}) {
-	logf(ctx, errC, "ERROR", msg, args...)
+	registryFor(ctx).Errorf(ctx, msg, args...)
 }
 
 // Fatalf prints an error and immediately stops execution.
 func Fatalf(ctx context.Context, msg string, args ...interface{}) {
-	logf(ctx, fatalC, "FATAL", msg, args...)
-	os.Exit(1)
+	registryFor(ctx).Fatalf(ctx, msg, args...)
 }
 
 // Trace allows nested logging of operations.
 // TODO: make a version of this that can log across multiple pageviews/RPCs.
 func Trace(ctx context.Context, name string, fn func(ctx context.Context) error) error {
-	switch ctx.(type) {
-	case LoggingContext:
-		c := ctx.(LoggingContext)
-
-		if n, ok := c.tags["span_id"]; ok {
-			ctx = WithAll(ctx, Tag{
-				K:        "parent_id",
-				V:        n[0],
-				Override: true,
-			})
-		}
-	default:
-	}
-
-	spanID, err := uuid.NewRandom()
-	if err != nil {
-		Errorf(ctx, "could not generate span ID: %v", err)
-		return err
-	}
-
-	start := time.Now()
-	ctx = WithAll(ctx,
-		Tag{
-			K:        "span_id",
-			V:        spanID.String(),
-			Override: true,
-		},
-		Tag{
-			K:        "name",
-			V:        name,
-			Override: true,
-		},
-	)
-	err = fn(ctx)
-
-	end := time.Now()
-	ctx = WithAll(ctx,
-		Tag{
-			K:        "dur_ms",
-			V:        end.Sub(start).Milliseconds(),
-			Override: true,
-		},
-		Tag{
-			K:        "end_time",
-			V:        end.Unix(),
-			Override: true,
-		},
-		Tag{
-			K:        "start_time",
-			V:        start.Unix(),
-			Override: true,
-		},
-	)
-
-	if err == nil {
-		Infof(ctx, "span")
-	} else {
-		Errorf(ctx, "span")
-	}
-	return err
+	return registryFor(ctx).Trace(ctx, name, fn)
 }
 
 // AppendToTrace is a helper function to append information to a traced
 // context. It's mostly used for logging request information for
-// browser clients.
+// browser clients. The tag shows up on `ctx`, and on contexts made from it
+// afterwards; contexts that were already made from it don't get it.
 func AppendToTrace(ctx context.Context, k string, v interface{}) {
-	switch ctx.(type) {
-	case LoggingContext:
-		c := ctx.(LoggingContext)
-		c.order = append(c.order, k)
-
-		c.tags[k] = []interface{}{v}
-		ctx = c
-	default:
-		// Upgrade contexts here? There's very little circumstances where this
-		// would happen.
+	registryFor(ctx).AppendToTrace(ctx, k, v)
+}
+
+// LogAll logs the same entry once for each of `ctxs`, with that context's
+// tags, for operations that belong to several of them at once, like a batch
+// built from many requests. Each context's own logger is used, and a
+// FATAL entry exits only once all of them have been logged.
+func LogAll(ctxs []context.Context, level Level, msg string, args ...interface{}) {
+	for _, ctx := range ctxs {
+		r := registryFor(ctx)
+		if r.enabled(level) {
+			r.logf(ctx, level, msg, args...)
+		}
+	}
+
+	if level == LevelFatal {
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,121 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// benchSink throws away everything it's given.
+type benchSink struct{}
+
+func (benchSink) Log(LogEntry) error { return nil }
+
+// benchRegistry returns a registry which only logs to a benchSink, so that
+// benchmarks don't measure writing to the terminal.
+func benchRegistry() *Registry {
+	r := NewRegistry()
+	r.sinks = map[string]Sink{"bench": benchSink{}}
+	return r
+}
+
+// withTags returns a context with `n` distinct tags.
+func withTags(n int) context.Context {
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		ctx = With(ctx, fmt.Sprintf("key%d", i), i)
+	}
+
+	return ctx
+}
+
+func BenchmarkWithFresh(b *testing.B) {
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		With(ctx, "key", "value")
+	}
+}
+
+func BenchmarkWithExisting10(b *testing.B) {
+	ctx := withTags(10)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		With(ctx, "key", "value")
+	}
+}
+
+func BenchmarkWithAll5(b *testing.B) {
+	ctx := context.Background()
+	tags := []Tag{
+		{K: "a", V: 1},
+		{K: "b", V: "two"},
+		{K: "c", V: 3.0},
+		{K: "d", V: true},
+		{K: "e", V: nil},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		WithAll(ctx, tags...)
+	}
+}
+
+func BenchmarkWithAllEmpty(b *testing.B) {
+	ctx := withTags(10)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		WithAll(ctx)
+	}
+}
+
+func BenchmarkClone20(b *testing.B) {
+	ctx := withTags(20)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Clone(ctx)
+	}
+}
+
+func BenchmarkLogfNoopSink(b *testing.B) {
+	r := benchRegistry()
+	ctx := withTags(5)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.logf(ctx, LevelInfo, "hello %s", "world")
+	}
+}
+
+func BenchmarkTraceEmpty(b *testing.B) {
+	r := benchRegistry()
+	ctx := withTags(5)
+	fn := func(context.Context) error { return nil }
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Trace(ctx, "bench", fn)
+	}
+}
+
+func BenchmarkConsoleSink(b *testing.B) {
+	r := benchRegistry()
+	cs := &ConsoleSink{out: io.Discard}
+	entry := r.newEntry(withTags(10), LevelInfo, "hello %s", "world")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cs.Log(entry)
+	}
+}
@@ -0,0 +1,74 @@
+package ctxlogtest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// AssertNoEntriesAtLevel fails the test if `sink` has any entries at
+// `level`, and prints them.
+func AssertNoEntriesAtLevel(t testing.TB, sink *MockSink, level string) {
+	t.Helper()
+
+	if entries := sink.EntriesAtLevel(level); len(entries) > 0 {
+		t.Errorf("expected no %s entries, got %d:\n%s", strings.ToUpper(level), len(entries), formatEntries(entries))
+	}
+}
+
+// AssertEntryCount fails the test if `sink` doesn't have exactly `expected`
+// entries at `level`.
+func AssertEntryCount(t testing.TB, sink *MockSink, level string, expected int) {
+	t.Helper()
+
+	if entries := sink.EntriesAtLevel(level); len(entries) != expected {
+		t.Errorf("expected %d %s entries, got %d:\n%s", expected, strings.ToUpper(level), len(entries), formatEntries(sink.Entries()))
+	}
+}
+
+// AssertEntryMatches fails the test unless `sink` has an entry at `level`
+// whose message matches the regular expression `msgPattern`.
+func AssertEntryMatches(t testing.TB, sink *MockSink, level string, msgPattern string) {
+	t.Helper()
+
+	re, err := regexp.Compile(msgPattern)
+	if err != nil {
+		t.Errorf("invalid message pattern %q: %v", msgPattern, err)
+		return
+	}
+
+	for _, e := range sink.EntriesAtLevel(level) {
+		if re.MatchString(e.Message) {
+			return
+		}
+	}
+
+	t.Errorf("expected a %s entry matching %q, got:\n%s", strings.ToUpper(level), msgPattern, formatEntries(sink.Entries()))
+}
+
+// formatEntries prints entries one per line, for failure messages.
+func formatEntries(entries []ctxlog.LogEntry) string {
+	if len(entries) == 0 {
+		return "\t(no entries)"
+	}
+
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = "\t" + formatEntry(e)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatEntry prints an entry's level, message and tags on one line.
+func formatEntry(e ctxlog.LogEntry) string {
+	line := fmt.Sprintf("[%s] %s", e.Level, e.Message)
+	for _, tag := range e.Tags {
+		line += fmt.Sprintf(" %s=%v", tag.K, tag.V)
+	}
+
+	return line
+}
@@ -0,0 +1,60 @@
+// Package ctxlogtest helps test code that logs with ctxlog, by capturing the
+// entries it logs and checking them.
+package ctxlogtest
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// MockSink keeps every entry it's given in memory. It is safe for concurrent
+// use.
+type MockSink struct {
+	mu      sync.Mutex
+	entries []ctxlog.LogEntry
+}
+
+// NewMockSink creates an empty MockSink.
+func NewMockSink() *MockSink {
+	return &MockSink{}
+}
+
+// Log stores the entry.
+func (ms *MockSink) Log(entry ctxlog.LogEntry) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.entries = append(ms.entries, entry)
+	return nil
+}
+
+// Entries returns the stored entries, oldest first.
+func (ms *MockSink) Entries() []ctxlog.LogEntry {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return append([]ctxlog.LogEntry(nil), ms.entries...)
+}
+
+// EntriesAtLevel returns the stored entries with the given level name (e.g.
+// "ERROR"), oldest first.
+func (ms *MockSink) EntriesAtLevel(level string) []ctxlog.LogEntry {
+	var ret []ctxlog.LogEntry
+	for _, e := range ms.Entries() {
+		if strings.EqualFold(e.Level.String(), level) {
+			ret = append(ret, e)
+		}
+	}
+
+	return ret
+}
+
+// Reset throws away the stored entries.
+func (ms *MockSink) Reset() {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.entries = nil
+}
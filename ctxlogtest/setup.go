@@ -0,0 +1,45 @@
+package ctxlogtest
+
+import (
+	"testing"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// Setup adds a MockSink named "test" to the package-level logger, and removes
+// it again when the test finishes.
+//
+//	sink := ctxlogtest.Setup(t)
+//	doWork(ctx)
+//	ctxlogtest.AssertNoEntriesAtLevel(t, sink, "ERROR")
+//
+// Tests using Setup share the package-level logger, so they can't run in
+// parallel with each other.
+func Setup(t testing.TB) *MockSink {
+	t.Helper()
+
+	sink := NewMockSink()
+	ctxlog.UseSink("test", sink)
+	t.Cleanup(func() {
+		ctxlog.RemoveSink("test")
+		sink.Reset()
+	})
+
+	return sink
+}
+
+// Silence stops the package-level logger from printing to the console until
+// the test finishes.
+func Silence(t testing.TB) {
+	t.Helper()
+
+	console, ok := ctxlog.LookupSink("console")
+	ctxlog.UseSink("console", ctxlog.NewNoopSink())
+	t.Cleanup(func() {
+		if ok {
+			ctxlog.UseSink("console", console)
+		} else {
+			ctxlog.RemoveSink("console")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package ctxlogtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// AssertTagPresent fails the test if `ctx` doesn't have the tag `key`.
+func AssertTagPresent(t testing.TB, ctx context.Context, key string) {
+	t.Helper()
+
+	if _, ok := ctxlog.TagValues(ctx, key); !ok {
+		t.Errorf("expected tag %q to be present, got %s", key, describeTags(ctx))
+	}
+}
+
+// AssertTagAbsent fails the test if `ctx` has the tag `key`.
+func AssertTagAbsent(t testing.TB, ctx context.Context, key string) {
+	t.Helper()
+
+	if vals, ok := ctxlog.TagValues(ctx, key); ok {
+		t.Errorf("expected tag %q to be absent, got %v", key, vals)
+	}
+}
+
+// AssertTagEquals fails the test unless the tag `key` in `ctx` has the
+// value `expected`. For tags with more than one value, `expected` should be
+// an []interface{} of all of them.
+func AssertTagEquals(t testing.TB, ctx context.Context, key string, expected interface{}) {
+	t.Helper()
+
+	vals, ok := ctxlog.TagValues(ctx, key)
+	if !ok {
+		t.Errorf("expected tag %q to be %#v, but it isn't present in %s", key, expected, describeTags(ctx))
+		return
+	}
+
+	var actual interface{} = vals
+	if len(vals) == 1 {
+		actual = vals[0]
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected tag %q to be %#v, got %#v", key, expected, actual)
+	}
+}
+
+// AssertTagCount fails the test unless `ctx` has exactly `expectedCount`
+// distinct tags.
+func AssertTagCount(t testing.TB, ctx context.Context, expectedCount int) {
+	t.Helper()
+
+	if n := len(ctxlog.Tags(ctx)); n != expectedCount {
+		t.Errorf("expected %d tags, got %d: %s", expectedCount, n, describeTags(ctx))
+	}
+}
+
+// describeTags prints the tags of `ctx` for failure messages.
+func describeTags(ctx context.Context) string {
+	var b strings.Builder
+	ctxlog.PrintTags(ctx, &b)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// TraceID returns the `trace_id` tag of `ctx`. It panics if there isn't one,
+// so that tests expecting one fail loudly.
+func TraceID(ctx context.Context) string {
+	return mustTag(ctx, "trace_id")
+}
+
+// SpanID returns the `span_id` tag of `ctx`. It panics if there isn't one.
+func SpanID(ctx context.Context) string {
+	return mustTag(ctx, "span_id")
+}
+
+// HasTrace reports whether `ctx` has a `trace_id` tag.
+func HasTrace(ctx context.Context) bool {
+	_, ok := ctxlog.FirstTagValue(ctx, "trace_id")
+	return ok
+}
+
+func mustTag(ctx context.Context, key string) string {
+	v, ok := ctxlog.FirstTagValue(ctx, key)
+	if !ok {
+		panic(fmt.Sprintf("ctxlogtest: context has no %s tag: %s", key, describeTags(ctx)))
+	}
+
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,32 @@
+package ctxlogtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+func TestTraceIDInsideTrace(t *testing.T) {
+	Setup(t)
+
+	var root, child string
+	err := ctxlog.Trace(context.Background(), "outer", func(ctx context.Context) error {
+		if !HasTrace(ctx) {
+			t.Fatalf("span has no trace: %s", describeTags(ctx))
+		}
+		root = TraceID(ctx)
+
+		return ctxlog.Trace(ctx, "inner", func(ctx context.Context) error {
+			child = TraceID(ctx)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if root == "" || child != root {
+		t.Errorf("child span has trace ID %q, want %q", child, root)
+	}
+}
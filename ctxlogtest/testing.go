@@ -0,0 +1,62 @@
+package ctxlogtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// TestingSink writes entries to a test's log with t.Log, so they're only
+// shown when the test fails or is run with -v. Entries logged after the
+// test has finished are dropped, since t.Log can't be used then.
+type TestingSink struct {
+	mu   sync.Mutex
+	t    testing.TB
+	done bool
+}
+
+// NewTestingSink creates a sink which writes to the log of `t`.
+func NewTestingSink(t testing.TB) *TestingSink {
+	ts := &TestingSink{t: t}
+	t.Cleanup(func() {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+
+		ts.done = true
+	})
+
+	return ts
+}
+
+// Log writes the entry to the test's log.
+func (ts *TestingSink) Log(entry ctxlog.LogEntry) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if !ts.done {
+		ts.t.Log(formatEntry(entry))
+	}
+
+	return nil
+}
+
+// WriteTo creates a sink which writes entries to the log of `t`, like
+// `[INFO] msg key1=val1 key2=val2`, for adding to a Registry by hand. It's
+// safe to use from multiple goroutines.
+func WriteTo(t *testing.T) ctxlog.Sink {
+	return NewTestingSink(t)
+}
+
+// WithTest returns a context which logs to the log of `t`, including debug
+// entries, instead of the console. Only logs made with the returned context
+// (or contexts made from it) go there, so tests using it can run in
+// parallel.
+func WithTest(ctx context.Context, t testing.TB) context.Context {
+	r := ctxlog.NewRegistry(ctxlog.WithDebug(true))
+	r.RemoveSink("console")
+	r.UseSink("test", NewTestingSink(t))
+
+	return ctxlog.ContextWithLogger(ctx, r)
+}
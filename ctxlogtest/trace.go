@@ -0,0 +1,69 @@
+package ctxlogtest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// SpanCapture is what CaptureTrace saw of a span.
+type SpanCapture struct {
+	Name       string
+	DurationMs int64
+	Tags       []ctxlog.Tag
+	Error      error
+	StartTime  time.Time
+}
+
+// CaptureTrace runs `fn` in a span named `name`, like ctxlog.Trace, and
+// returns what `fn` returned along with the span that was logged. Anything
+// logged inside the span goes to the test's log rather than the usual
+// sinks.
+func CaptureTrace(t testing.TB, ctx context.Context, name string, fn func(context.Context) error) (error, SpanCapture) {
+	t.Helper()
+
+	sink := NewMockSink()
+	r := ctxlog.NewRegistry(ctxlog.WithDebug(true))
+	r.RemoveSink("console")
+	r.UseSink("capture", sink)
+	r.UseSink("test", NewTestingSink(t))
+
+	start := time.Now()
+	err := r.Trace(ctxlog.ContextWithLogger(ctx, r), name, fn)
+
+	capture := SpanCapture{Name: name, Error: err, StartTime: start}
+
+	// Spans inside this one may have the same name, but they end first.
+	entries := sink.Entries()
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Message != "span" || !hasTag(e, "name", name) {
+			continue
+		}
+
+		capture.Tags = e.Tags
+		for _, tag := range e.Tags {
+			if tag.K == "dur_ms" {
+				capture.DurationMs, _ = tag.V.(int64)
+			}
+		}
+		return err, capture
+	}
+
+	t.Errorf("span %q was not logged", name)
+	return err, capture
+}
+
+// hasTag reports whether `e` has the tag `k` with the value `v`.
+func hasTag(e ctxlog.LogEntry, k string, v interface{}) bool {
+	for _, tag := range e.Tags {
+		if tag.K == k && reflect.DeepEqual(tag.V, v) {
+			return true
+		}
+	}
+
+	return false
+}
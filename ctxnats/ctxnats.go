@@ -0,0 +1,45 @@
+// Package ctxnats carries ctxlog contexts across NATS, in message headers,
+// so that subscribers' logs can be tied back to whoever published the
+// message.
+package ctxnats
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// headerPrefix keeps our headers apart from any others on the message.
+const headerPrefix = "Ctxlog-"
+
+// InjectNATSHeaders adds the context's trace ID, span ID, instance ID and any
+// tags marked with ctxlog.Propagate to the headers of `msg`. Headers work
+// the same for core NATS and JetStream, but need a server running NATS 2.2
+// or later.
+func InjectNATSHeaders(ctx context.Context, msg *nats.Msg) {
+	if msg.Header == nil {
+		msg.Header = nats.Header{}
+	}
+
+	for k, v := range ctxlog.PropagationFields(ctx) {
+		msg.Header.Set(headerPrefix+k, v)
+	}
+}
+
+// ExtractNATSHeaders returns `ctx` with the fields that InjectNATSHeaders
+// added to the headers of `msg`.
+func ExtractNATSHeaders(ctx context.Context, msg *nats.Msg) context.Context {
+	fields := map[string]string{}
+	for k, v := range msg.Header {
+		if len(v) == 0 || !strings.HasPrefix(k, headerPrefix) {
+			continue
+		}
+
+		fields[strings.TrimPrefix(k, headerPrefix)] = v[0]
+	}
+
+	return ctxlog.FromPropagation(ctx, fields)
+}
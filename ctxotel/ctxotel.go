@@ -0,0 +1,84 @@
+// Package ctxotel bridges ctxlog contexts and OpenTelemetry spans, so that
+// logs and traces from the same request can be tied together whichever one
+// started it.
+package ctxotel
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// OTelBridge returns `ctx` with the trace and span IDs of the OpenTelemetry
+// span in it as the `trace_id` and `span_id` tags. Contexts without a valid
+// span are returned as they are.
+func OTelBridge(ctx context.Context) context.Context {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return ctx
+	}
+
+	ctx = ctxlog.WithTraceID(ctx, sc.TraceID().String())
+	return ctxlog.WithSpanID(ctx, sc.SpanID().String())
+}
+
+// InjectOTelSpan returns `ctx` with `span` in it, for libraries that are
+// instrumented with OpenTelemetry. If `span` is nil, a non-recording span is
+// made from the context's `trace_id` and `span_id` tags instead, which
+// OpenTelemetry will use as the parent of any spans it starts. Those are set
+// inside ctxlog.Trace and its variants, or by WithTraceID and WithSpanID;
+// contexts without both are returned as they are. The context's tags are
+// kept either way.
+func InjectOTelSpan(ctx context.Context, span trace.Span) context.Context {
+	if span != nil {
+		return ctxlog.WithContext(trace.ContextWithSpan(ctx, span), ctx)
+	}
+
+	sc, ok := spanContextOf(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return ctxlog.WithContext(trace.ContextWithRemoteSpanContext(ctx, sc), ctx)
+}
+
+// spanContextOf converts ctxlog's IDs to OpenTelemetry's. ctxlog makes its
+// IDs from UUIDs, which are the same size as an OpenTelemetry trace ID, but
+// twice the size of a span ID, so only the first half of a span ID is used.
+func spanContextOf(ctx context.Context) (trace.SpanContext, bool) {
+	fields := ctxlog.PropagationFields(ctx)
+
+	var traceID trace.TraceID
+	if !decodeID(fields["trace_id"], traceID[:]) {
+		return trace.SpanContext{}, false
+	}
+
+	var spanID trace.SpanID
+	if !decodeID(fields["span_id"], spanID[:]) {
+		return trace.SpanContext{}, false
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	return sc, sc.IsValid()
+}
+
+// decodeID fills `dst` from the start of the hex ID `id`, ignoring any
+// dashes.
+func decodeID(id string, dst []byte) bool {
+	id = strings.ReplaceAll(id, "-", "")
+	if len(id) < 2*len(dst) {
+		return false
+	}
+
+	_, err := hex.Decode(dst, []byte(id[:2*len(dst)]))
+	return err == nil
+}
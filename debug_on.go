@@ -0,0 +1,7 @@
+//go:build ctxlog_debug
+
+package ctxlog
+
+// debugChecks turns on extra checks for misuse of ctxlog. Build with
+// `-tags ctxlog_debug` to enable them.
+const debugChecks = true
@@ -0,0 +1,73 @@
+package ctxlog
+
+import (
+	"context"
+)
+
+// RegisterDeprecatedKey helps with renaming a tag from `old` to `new`: tags
+// added with the key `old` are also added as `new`, unless the context
+// already has a `new` tag. Each use of `old` is logged at the debug level,
+// so that the places still using it can be found.
+//
+// Unlike RegisterTagAlias, which copies tags when entries are logged, this
+// copies them when they're added, so that code reading `new` from the
+// context sees it too.
+func RegisterDeprecatedKey(old, new string) {
+	std.RegisterDeprecatedKey(old, new)
+}
+
+// RegisterDeprecatedKey makes tags added with the key `old` through this
+// Registry also be added as `new`.
+func (r *Registry) RegisterDeprecatedKey(old, new string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.deprecated == nil {
+		r.deprecated = map[string]string{}
+	}
+	r.deprecated[old] = new
+}
+
+// withReplacements returns `tags` with a copy of each one that has a
+// deprecated key under its new key, unless `ctx` or `tags` already have
+// that key.
+func (r *Registry) withReplacements(ctx context.Context, tags []Tag) []Tag {
+	r.mu.RLock()
+	deprecated := r.deprecated
+	r.mu.RUnlock()
+
+	if len(deprecated) == 0 {
+		return tags
+	}
+
+	var extra []Tag
+	for _, t := range tags {
+		replacement, ok := deprecated[t.K]
+		if !ok {
+			continue
+		}
+		r.Debugf(ctx, "tag key %q is deprecated, use %q instead", t.K, replacement)
+
+		if _, exists := tagValues(ctx, replacement); exists || hasKey(tags, replacement) || hasKey(extra, replacement) {
+			continue
+		}
+		extra = append(extra, Tag{K: replacement, V: t.V, Override: t.Override})
+	}
+
+	if extra == nil {
+		return tags
+	}
+
+	return append(append([]Tag(nil), tags...), extra...)
+}
+
+// hasKey reports whether any of `tags` has the key `k`.
+func hasKey(tags []Tag, k string) bool {
+	for _, t := range tags {
+		if t.K == k {
+			return true
+		}
+	}
+
+	return false
+}
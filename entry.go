@@ -0,0 +1,188 @@
+package ctxlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// LogEntry is a single log event, as it is handed to each sink.
+type LogEntry struct {
+	Time    time.Time
+	Level   Level
+	Message string
+
+	// Tags carries the tags from the logging context, in the order they were
+	// added. Tags with more than one value have all of them as an
+	// []interface{}.
+	Tags []Tag
+
+	// The instance ID of the registry that logged this entry.
+	InstanceID string
+}
+
+// ToJSON returns a representation of the entry suitable for logging to an
+// external database.
+func (e LogEntry) ToJSON() map[string]interface{} {
+	tags := make(map[string]interface{}, len(e.Tags))
+	for _, t := range e.Tags {
+		tags[t.K] = t.V
+	}
+
+	return map[string]interface{}{
+		"time":        e.Time.Format(time.RFC3339Nano),
+		"level":       e.Level.String(),
+		"msg":         e.Message,
+		"tags":        tags,
+		"instance_id": e.InstanceID,
+	}
+}
+
+// ToJSONFlat is like ToJSON, but puts the tags at the top level next to the
+// entry's own fields, which take precedence if the names clash. Some log
+// stores are easier to query this way.
+func (e LogEntry) ToJSONFlat() map[string]interface{} {
+	ret := make(map[string]interface{}, len(e.Tags)+4)
+	for _, t := range e.Tags {
+		ret[t.K] = t.V
+	}
+
+	ret["time"] = e.Time.Format(time.RFC3339Nano)
+	ret["level"] = e.Level.String()
+	ret["msg"] = e.Message
+	ret["instance_id"] = e.InstanceID
+
+	return ret
+}
+
+// MarshalMsgpack encodes the entry as a MessagePack map, with the same keys
+// as ToJSONFlat. MessagePack is much cheaper to produce than JSON, for sinks
+// that can take it.
+func (e LogEntry) MarshalMsgpack() ([]byte, error) {
+	return msgpack.Marshal(e.ToJSONFlat())
+}
+
+// MarshalJSON encodes the entry as returned by ToJSON.
+func (e LogEntry) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.ToJSON())
+}
+
+// MarshalText encodes the entry as a logfmt line, without the trailing
+// newline.
+func (e LogEntry) MarshalText() ([]byte, error) {
+	line, err := LogfmtFormatter{}.Format(e)
+	return bytes.TrimSuffix(line, []byte("\n")), err
+}
+
+// NewLogEntry builds the entry that logging `msg` at `level` with `ctx`
+// would hand to each sink, including any global tags and aliases, without
+// logging it.
+func NewLogEntry(ctx context.Context, level Level, msg string, args ...interface{}) LogEntry {
+	return registryFor(ctx).NewLogEntry(ctx, level, msg, args...)
+}
+
+// NewLogEntry builds the entry that logging `msg` at `level` through this
+// Registry would produce, without logging it.
+func (r *Registry) NewLogEntry(ctx context.Context, level Level, msg string, args ...interface{}) LogEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.applyAliases(r.applyGlobals(r.newEntry(ctx, level, msg, args...)))
+}
+
+// newEntry builds the entry for a log call on `ctx`.
+func (r *Registry) newEntry(ctx context.Context, level Level, msg string, args ...interface{}) LogEntry {
+	tags := tagsOf(ctx)
+	if r.omitEmpty {
+		tags = omitEmpty(tags)
+	}
+
+	return LogEntry{
+		Time:       time.Now(),
+		Level:      level,
+		Message:    fmt.Sprintf(msg, args...),
+		Tags:       tags,
+		InstanceID: r.id.String(),
+	}
+}
+
+// omitEmpty returns the tags in `tags` whose values aren't the zero value for
+// their type.
+func omitEmpty(tags []Tag) []Tag {
+	ret := tags[:0]
+	for _, t := range tags {
+		if t.V == nil || reflect.ValueOf(t.V).IsZero() {
+			continue
+		}
+		ret = append(ret, t)
+	}
+
+	return ret
+}
+
+// tagsOf returns the tags carried by `ctx` in the order they were added.
+func tagsOf(ctx context.Context) []Tag {
+	lc, ok := ctx.(LoggingContext)
+	if !ok {
+		return nil
+	}
+
+	vals, order := lc.tags.load()
+	ret := make([]Tag, 0, len(order))
+	for _, k := range order {
+		val := resolve(vals[k])
+		if len(val) == 0 {
+			continue
+		}
+
+		// Special-case for single-item lists, to just use that single item.
+		// Helps preserve the normal expected formatting.
+		if len(val) == 1 {
+			ret = append(ret, Tag{K: k, V: val[0]})
+		} else {
+			ret = append(ret, Tag{K: k, V: val})
+		}
+	}
+
+	return ret
+}
+
+// lazyValue is a tag value that isn't known until the entry is logged, like
+// the status code of a response that hasn't been sent yet.
+type lazyValue interface {
+	// resolve returns the current value, and false if there isn't one yet.
+	resolve() (interface{}, bool)
+}
+
+// resolve replaces any lazy values in `vals` with their current values,
+// dropping the ones that don't have one yet.
+func resolve(vals []interface{}) []interface{} {
+	lazy := false
+	for _, v := range vals {
+		if _, ok := v.(lazyValue); ok {
+			lazy = true
+			break
+		}
+	}
+
+	if !lazy {
+		return vals
+	}
+
+	ret := make([]interface{}, 0, len(vals))
+	for _, v := range vals {
+		if lv, ok := v.(lazyValue); ok {
+			if v, ok = lv.resolve(); !ok {
+				continue
+			}
+		}
+		ret = append(ret, v)
+	}
+
+	return ret
+}
@@ -0,0 +1,133 @@
+package ctxlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TaggedError is implemented by errors that carry structured data, like the
+// status code of a failed request or the field that failed validation.
+// WithError and WithErrorChain add their tags to the context along with the
+// error itself.
+type TaggedError interface {
+	error
+	Tags() []Tag
+}
+
+// WithError adds `err` to the context as the `error` tag. If `err` is, or
+// wraps, a TaggedError, its tags are added too. A nil error leaves the
+// context as it is.
+func WithError(ctx context.Context, err error) context.Context {
+	return registryFor(ctx).WithError(ctx, err)
+}
+
+// WithError adds `err` to the context as the `error` tag, through this
+// Registry.
+func (r *Registry) WithError(ctx context.Context, err error) context.Context {
+	if err == nil {
+		return ctx
+	}
+
+	tags := []Tag{{K: "error", V: err.Error(), Override: true}}
+
+	var te TaggedError
+	if errors.As(err, &te) {
+		tags = append(tags, te.Tags()...)
+	}
+
+	return r.WithAll(ctx, tags...)
+}
+
+// WithErrorChain adds `err` to the context like WithError, along with the
+// message of every error it wraps, outermost first, as the multi-value tag
+// `error_chain`. Errors in the chain that are a TaggedError, or have a
+// `Fields() map[string]interface{}` method, have those tags added too.
+func WithErrorChain(ctx context.Context, err error) context.Context {
+	return registryFor(ctx).WithErrorChain(ctx, err)
+}
+
+// WithErrorChain adds `err` and the errors it wraps to the context, through
+// this Registry.
+func (r *Registry) WithErrorChain(ctx context.Context, err error) context.Context {
+	if err == nil {
+		return ctx
+	}
+
+	tags := []Tag{{K: "error", V: err.Error(), Override: true}}
+	var fields []Tag
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		tags = append(tags, Tag{K: "error_chain", V: e.Error(), Override: len(tags) == 1})
+
+		if te, ok := e.(TaggedError); ok {
+			fields = append(fields, te.Tags()...)
+		}
+		if f, ok := e.(interface{ Fields() map[string]interface{} }); ok {
+			fields = append(fields, sortedTags(f.Fields())...)
+		}
+	}
+
+	return r.WithAll(ctx, append(tags, fields...)...)
+}
+
+// WithErrors adds `errs`, like the problems found while validating
+// something, to the context: their messages as the multi-value tag
+// `error_msgs`, and how many there are as `error_count`. Errorf lists the
+// messages after its own. An empty `errs` leaves the context as it is.
+func WithErrors(ctx context.Context, errs []error) context.Context {
+	return registryFor(ctx).WithErrors(ctx, errs)
+}
+
+// WithErrors adds `errs` to the context, through this Registry.
+func (r *Registry) WithErrors(ctx context.Context, errs []error) context.Context {
+	if len(errs) == 0 {
+		return ctx
+	}
+
+	tags := []Tag{{K: "error_count", V: len(errs), Override: true}}
+	for i, err := range errs {
+		msg := "<nil>"
+		if err != nil {
+			msg = err.Error()
+		}
+		tags = append(tags, Tag{K: "error_msgs", V: msg, Override: i == 0})
+	}
+
+	return r.WithAll(ctx, tags...)
+}
+
+// withErrorList returns the message and arguments for Errorf, with the
+// context's `error_msgs` listed after the message if it has any.
+func withErrorList(ctx context.Context, msg string, args []interface{}) (string, []interface{}) {
+	// WithErrors puts the tag in the context's namespace, like any other.
+	msgs, ok := tagValues(ctx, namespaced(ctx, "error_msgs"))
+	if !ok || len(msgs) == 0 {
+		return msg, args
+	}
+
+	list := make([]string, len(msgs))
+	for i, m := range msgs {
+		list[i] = fmt.Sprint(m)
+	}
+
+	return "%s: [%s]", []interface{}{fmt.Sprintf(msg, args...), strings.Join(list, "; ")}
+}
+
+// sortedTags turns `m` into tags which override any existing values, in key
+// order so that they print the same way every time.
+func sortedTags(m map[string]interface{}) []Tag {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tags := make([]Tag, len(keys))
+	for i, k := range keys {
+		tags[i] = Tag{K: k, V: m[k], Override: true}
+	}
+
+	return tags
+}
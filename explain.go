@@ -0,0 +1,79 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ExplainContext describes how the tags of `ctx` were built up, for
+// debugging. Each line of the tree is one call that added tags, outermost
+// first, and each tag is shown with all of the values it has now:
+//
+//	LoggingContext
+//	└─ request_id=[abc] (1 value)
+//	   └─ span_id=[abc123] (1 value) │ parent_id=[def456] (1 value)
+//
+// Tags that were copied in all at once, like by Clone, show up as a single
+// line. It's not meant to be parsed, and the format may change.
+func ExplainContext(ctx context.Context) string {
+	lc, ok := ctx.(LoggingContext)
+	if !ok {
+		return "(not a LoggingContext)"
+	}
+
+	vals, _ := lc.tags.load()
+
+	// Walk back to the root, since the tree is printed from there.
+	var layers [][]string
+	for ts := lc.tags; ts != nil; ts = ts.parent {
+		var keys []string
+		if ts.added != nil {
+			for _, t := range ts.added {
+				keys = append(keys, t.K)
+			}
+		} else {
+			// A set made with all of its tags already worked out.
+			_, order := ts.load()
+			keys = order
+		}
+
+		layers = append(layers, keys)
+		if ts.added == nil {
+			break
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("LoggingContext")
+	if lc.ns != "" {
+		fmt.Fprintf(&b, " (namespace %q)", lc.ns)
+	}
+	if len(layers) == 0 {
+		b.WriteString("\n└─ (no tags)")
+	}
+
+	for depth := 0; depth < len(layers); depth++ {
+		keys := layers[len(layers)-1-depth]
+
+		parts := make([]string, 0, len(keys))
+		seen := map[string]bool{}
+		for _, k := range keys {
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+
+			v := resolve(vals[k])
+			noun := "values"
+			if len(v) == 1 {
+				noun = "value"
+			}
+			parts = append(parts, fmt.Sprintf("%s=%v (%d %s)", k, v, len(v), noun))
+		}
+
+		fmt.Fprintf(&b, "\n%s└─ %s", strings.Repeat("   ", depth), strings.Join(parts, " │ "))
+	}
+
+	return b.String()
+}
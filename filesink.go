@@ -0,0 +1,89 @@
+package ctxlog
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FileSink appends entries to a file as JSON lines. It works with external
+// log rotation, like logrotate: on Unix, the file is reopened at the same
+// path when the process gets a SIGHUP, and Reopen does the same anywhere.
+type FileSink struct {
+	sinkCounters
+	sinkReporter
+
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+
+	// Closed to stop listening for signals.
+	stop chan struct{}
+}
+
+// NewFileSink creates a sink which appends entries to the file at `path`,
+// creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := openLogFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := &FileSink{path: path, file: f, stop: make(chan struct{})}
+	fs.reopenOnSignal()
+	return fs, nil
+}
+
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+}
+
+// Log appends the entry to the file as a line of JSON.
+func (fs *FileSink) Log(entry LogEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		fs.record(0, err)
+		return err
+	}
+	line = append(line, '\n')
+
+	fs.mu.Lock()
+	n, err := fs.file.Write(line)
+	fs.mu.Unlock()
+
+	fs.record(n, err)
+	return err
+}
+
+// Reopen opens the file at the sink's path again, and switches to writing to
+// it. Call it after the file has been moved away for rotation. If the file
+// can't be opened, the sink keeps writing to the old one.
+func (fs *FileSink) Reopen() error {
+	f, err := openLogFile(fs.path)
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	old := fs.file
+	fs.file = f
+	fs.mu.Unlock()
+
+	return old.Close()
+}
+
+// Close stops listening for signals, and closes the file.
+func (fs *FileSink) Close() error {
+	select {
+	case <-fs.stop:
+		return nil
+	default:
+		close(fs.stop)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return fs.file.Close()
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package ctxlog
+
+// reopenOnSignal does nothing where there's no SIGHUP; call Reopen instead.
+func (fs *FileSink) reopenOnSignal() {}
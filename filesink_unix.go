@@ -0,0 +1,31 @@
+//go:build unix
+
+package ctxlog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// reopenOnSignal reopens the file whenever the process gets a SIGHUP, until
+// the sink is closed.
+func (fs *FileSink) reopenOnSignal() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(hup)
+
+		for {
+			select {
+			case <-fs.stop:
+				return
+			case <-hup:
+				if err := fs.Reopen(); err != nil {
+					fs.report("Could not reopen log file '%s': %v", fs.path, err)
+				}
+			}
+		}
+	}()
+}
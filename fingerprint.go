@@ -0,0 +1,53 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// ContextFingerprint returns a hash of the tags in `ctx`. Contexts with the
+// same tags and values have the same fingerprint, whatever order the tags
+// were added in.
+func ContextFingerprint(ctx context.Context) uint64 {
+	tags := tagsOf(ctx)
+	sort.Slice(tags, func(i, j int) bool { return tags[i].K < tags[j].K })
+
+	h := fnv.New64a()
+	for _, t := range tags {
+		fmt.Fprintf(h, "%s=%v\x00", t.K, t.V)
+	}
+
+	return h.Sum64()
+}
+
+// trackParent notes that a span is starting in `ctx`, and warns if another
+// span started in a context with the same tags is still running. That's
+// usually a context variable that should have been replaced by a span's
+// context, but wasn't. The returned function must be called when the span
+// ends.
+func (r *Registry) trackParent(ctx context.Context, name string) func() {
+	fp := ContextFingerprint(ctx)
+
+	r.spansMu.Lock()
+	if r.activeParents == nil {
+		r.activeParents = map[uint64]int{}
+	}
+	r.activeParents[fp]++
+	running := r.activeParents[fp] - 1
+	r.spansMu.Unlock()
+
+	if running > 0 {
+		r.Warnf(ctx, "span %q started from the same context as %d other running span(s); was a span's context dropped?", name, running)
+	}
+
+	return func() {
+		r.spansMu.Lock()
+		defer r.spansMu.Unlock()
+
+		if r.activeParents[fp]--; r.activeParents[fp] <= 0 {
+			delete(r.activeParents, fp)
+		}
+	}
+}
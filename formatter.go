@@ -0,0 +1,28 @@
+package ctxlog
+
+// Formatter turns an entry into the bytes that a sink writes out.
+type Formatter interface {
+	Format(entry LogEntry) ([]byte, error)
+}
+
+// FormatEntry renders `entry` with `f`, for using an entry outside of a
+// sink, like in an error message.
+func FormatEntry(entry LogEntry, f Formatter) (string, error) {
+	b, err := f.Format(entry)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// tag returns the value of the tag `k` in the entry, if it has one.
+func (e LogEntry) tag(k string) (interface{}, bool) {
+	for _, t := range e.Tags {
+		if t.K == k {
+			return t.V, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,125 @@
+package ctxlog
+
+import (
+	runtimedebug "runtime/debug"
+	"strings"
+)
+
+// SetGlobalTag adds a tag to every entry that's logged, like the name of the
+// service. Tags carried by the context take precedence over global ones.
+func SetGlobalTag(k string, v interface{}) {
+	std.SetGlobalTag(k, v)
+}
+
+// SetGlobalTag adds a tag to every entry logged through this Registry.
+func (r *Registry) SetGlobalTag(k string, v interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setGlobalTag(k, v)
+}
+
+// setGlobalTag replaces the global tag `k`, or adds it at the end. Callers
+// must hold r.mu.
+func (r *Registry) setGlobalTag(k string, v interface{}) {
+	for i, t := range r.globals {
+		if t.K == k {
+			r.globals[i].V = v
+			return
+		}
+	}
+
+	r.globals = append(r.globals, Tag{K: k, V: v})
+}
+
+// SetServiceName sets the `service` tag on every entry. By default it's the
+// last part of the main module's path, if the binary was built with module
+// information.
+func SetServiceName(name string) {
+	std.SetServiceName(name)
+}
+
+// SetServiceName sets the `service` tag on every entry logged through this
+// Registry.
+func (r *Registry) SetServiceName(name string) {
+	r.SetGlobalTag("service", name)
+}
+
+// Version, Commit and BuildDate are logged as the `version`, `git_commit` and
+// `build_date` global tags when they're set, usually at build time:
+//
+//	go build -ldflags "-X github.com/silversupreme/ctxlog.Version=1.2.3 -X github.com/silversupreme/ctxlog.Commit=$(git rev-parse HEAD)"
+var (
+	Version   string
+	Commit    string
+	BuildDate string
+)
+
+// SetBuildInfo sets the `version`, `git_commit` and `build_date` tags on
+// every entry. Empty values are skipped, and the commit is shortened to 7
+// characters.
+func SetBuildInfo(version, commit, buildDate string) {
+	std.SetBuildInfo(version, commit, buildDate)
+}
+
+// SetBuildInfo sets the build information tags on every entry logged through
+// this Registry.
+func (r *Registry) SetBuildInfo(version, commit, buildDate string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setBuildInfo(version, commit, buildDate)
+}
+
+// setBuildInfo sets the build information tags. Callers must hold r.mu.
+func (r *Registry) setBuildInfo(version, commit, buildDate string) {
+	if version != "" {
+		r.setGlobalTag("version", version)
+	}
+	if commit != "" {
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		r.setGlobalTag("git_commit", commit)
+	}
+	if buildDate != "" {
+		r.setGlobalTag("build_date", buildDate)
+	}
+}
+
+// detectService sets the `service` and `service_version` tags from the
+// main module's build information, if there is any.
+func (r *Registry) detectService() {
+	info, ok := runtimedebug.ReadBuildInfo()
+	if !ok || info.Main.Path == "" {
+		return
+	}
+
+	r.setGlobalTag("service", info.Main.Path[strings.LastIndex(info.Main.Path, "/")+1:])
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		r.setGlobalTag("service_version", v)
+	}
+}
+
+// applyGlobals adds the global tags to the entry, ahead of its own tags.
+// Tags which the entry already has are left alone. Callers must hold r.mu.
+func (r *Registry) applyGlobals(entry LogEntry) LogEntry {
+	if len(r.globals) == 0 {
+		return entry
+	}
+
+	present := make(map[string]bool, len(entry.Tags))
+	for _, t := range entry.Tags {
+		present[t.K] = true
+	}
+
+	tags := make([]Tag, 0, len(r.globals)+len(entry.Tags))
+	for _, t := range r.globals {
+		if !present[t.K] {
+			tags = append(tags, t)
+		}
+	}
+	entry.Tags = append(tags, entry.Tags...)
+
+	return entry
+}
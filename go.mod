@@ -2,9 +2,21 @@ module github.com/silversupreme/ctxlog
 
 require (
 	github.com/fatih/color v1.7.0
-	github.com/google/uuid v1.1.1
+	github.com/google/uuid v1.3.1
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+	golang.org/x/oauth2 v0.16.0
+)
+
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/mattn/go-colorable v0.1.2 // indirect
-	github.com/pkg/errors v0.8.1
+	github.com/mattn/go-isatty v0.0.8 // indirect
+	github.com/stretchr/testify v1.8.4 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
+	google.golang.org/appengine v1.6.8 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
 )
 
-go 1.13
+go 1.21
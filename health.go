@@ -0,0 +1,79 @@
+package ctxlog
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// healthChecker is implemented by sinks that can tell whether they're able to
+// deliver entries right now.
+type healthChecker interface {
+	HealthCheck() error
+}
+
+// SinkHealth checks each sink that supports it, and returns the result keyed
+// by the name it was registered under. A nil error means the sink is
+// healthy.
+func SinkHealth() map[string]error {
+	return std.SinkHealth()
+}
+
+// SinkHealth checks each sink in this Registry that supports it.
+func (r *Registry) SinkHealth() map[string]error {
+	// Checks can go over the network, so don't hold up logging while they
+	// run.
+	r.mu.RLock()
+	checkers := map[string]healthChecker{}
+	for name, sink := range r.sinks {
+		if hc, ok := sink.(healthChecker); ok {
+			checkers[name] = hc
+		}
+	}
+	r.mu.RUnlock()
+
+	ret := make(map[string]error, len(checkers))
+	for name, hc := range checkers {
+		ret[name] = hc.HealthCheck()
+	}
+
+	return ret
+}
+
+// HealthCheck sends a HEAD request to the sink's URL. Anything other than a
+// server error counts as healthy, since endpoints that only accept POST will
+// usually reject it.
+func (hs *HTTPSink) HealthCheck() error {
+	if hs.net.err != nil {
+		return hs.net.err
+	}
+
+	return probe(hs.client, hs.url)
+}
+
+// HealthCheck sends a HEAD request to the Vector source's URL.
+func (vs *VectorSink) HealthCheck() error {
+	if vs.net.err != nil {
+		return vs.net.err
+	}
+
+	return probe(vs.client, vs.url)
+}
+
+func probe(client *http.Client, url string) error {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("unhealthy response from %s: %s", url, resp.Status)
+	}
+
+	return nil
+}
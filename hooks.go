@@ -0,0 +1,43 @@
+package ctxlog
+
+import (
+	"context"
+)
+
+// PreHook is called with each entry before it's handed to the sinks. It can
+// change the entry, or return false to stop it from being logged at all.
+type PreHook func(ctx context.Context, entry *LogEntry) bool
+
+// PostHook is called with each entry after all of the sinks have had it,
+// along with any errors they returned.
+type PostHook func(ctx context.Context, entry LogEntry, errs []error)
+
+// RegisterPreHook adds a hook which is called before each entry is logged.
+// Hooks are called in the order they were registered.
+func RegisterPreHook(fn PreHook) {
+	std.RegisterPreHook(fn)
+}
+
+// RegisterPostHook adds a hook which is called after each entry is logged.
+// Hooks are called in the order they were registered.
+func RegisterPostHook(fn PostHook) {
+	std.RegisterPostHook(fn)
+}
+
+// RegisterPreHook adds a hook which is called before each entry is logged
+// through this Registry.
+func (r *Registry) RegisterPreHook(fn PreHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.preHooks = append(r.preHooks, fn)
+}
+
+// RegisterPostHook adds a hook which is called after each entry is logged
+// through this Registry.
+func (r *Registry) RegisterPostHook(fn PostHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.postHooks = append(r.postHooks, fn)
+}
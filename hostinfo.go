@@ -0,0 +1,90 @@
+package ctxlog
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	hostOnce sync.Once
+	hostname string
+	pid      int
+
+	containerOnce sync.Once
+	containerID   string
+)
+
+// UseHostInfo adds the `hostname` and `pid` of this process as global tags.
+// They're only looked up once, the first time this is called.
+func UseHostInfo() {
+	std.UseHostInfo()
+}
+
+// UseHostInfo adds the `hostname` and `pid` of this process as global tags
+// on entries logged through this Registry.
+func (r *Registry) UseHostInfo() {
+	hostOnce.Do(func() {
+		hostname, _ = os.Hostname()
+		pid = os.Getpid()
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if hostname != "" {
+		r.setGlobalTag("hostname", hostname)
+	}
+	r.setGlobalTag("pid", pid)
+}
+
+// UseContainerInfo adds the ID of the Docker or Kubernetes container that
+// this process runs in as the `container_id` global tag. It's read from
+// /proc/self/cgroup, and nothing is added if it can't be found there.
+func UseContainerInfo() {
+	std.UseContainerInfo()
+}
+
+// UseContainerInfo adds the `container_id` global tag to entries logged
+// through this Registry.
+func (r *Registry) UseContainerInfo() {
+	containerOnce.Do(func() {
+		data, err := os.ReadFile("/proc/self/cgroup")
+		if err == nil {
+			containerID = parseContainerID(string(data))
+		}
+	})
+
+	if containerID == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.setGlobalTag("container_id", containerID)
+}
+
+// containerIDPattern matches the 64 character IDs used by Docker and the
+// container runtimes under Kubernetes, which show up in cgroup paths like
+// `/docker/<id>` or `/kubepods/.../cri-containerd-<id>.scope`.
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// parseContainerID finds the container ID in the contents of a
+// /proc/<pid>/cgroup file.
+func parseContainerID(cgroup string) string {
+	for _, line := range strings.Split(cgroup, "\n") {
+		// Lines are `hierarchy-ID:controllers:path`.
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+
+		if id := containerIDPattern.FindString(parts[2]); id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
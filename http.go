@@ -0,0 +1,173 @@
+package ctxlog
+
+import (
+	"context"
+	"net/http"
+	runtimedebug "runtime/debug"
+	"strings"
+	"sync/atomic"
+)
+
+// RecoverMiddleware recovers from panics in `next`, logs them at FATAL with
+// the request context's tags, the `panic_value` and the `stack_trace`, and
+// responds with a 500. The process keeps running.
+func RecoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			// This is how handlers ask the server to abort a response, so let
+			// it through.
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			ctx := req.Context()
+			r := registryFor(ctx)
+			ctx = r.withAll(ctx,
+				Tag{K: "panic_value", V: p, Override: true},
+				Tag{K: "stack_trace", V: string(runtimedebug.Stack()), Override: true},
+			)
+			r.logf(ctx, LevelFatal, "recovered from panic in HTTP handler")
+
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// ResponseRecorder wraps an http.ResponseWriter to keep track of the status
+// code and the number of bytes written.
+type ResponseRecorder struct {
+	http.ResponseWriter
+
+	status  int64
+	written int64
+}
+
+// NewResponseRecorder wraps `w` in a ResponseRecorder. The returned context
+// has `http_status` and `bytes_written` tags which follow the response, so
+// anything logged with it once the response has been written has them.
+func NewResponseRecorder(ctx context.Context, w http.ResponseWriter) (context.Context, *ResponseRecorder) {
+	rec := &ResponseRecorder{ResponseWriter: w}
+	ctx = registryFor(ctx).withAll(ctx,
+		Tag{K: "http_status", V: recorderStatus{rec}, Override: true},
+		Tag{K: "bytes_written", V: recorderWritten{rec}, Override: true},
+	)
+
+	return ctx, rec
+}
+
+// Status returns the status code of the response, or 0 if it hasn't been
+// sent yet.
+func (rr *ResponseRecorder) Status() int {
+	return int(atomic.LoadInt64(&rr.status))
+}
+
+// BytesWritten returns the size of the response body so far.
+func (rr *ResponseRecorder) BytesWritten() int64 {
+	return atomic.LoadInt64(&rr.written)
+}
+
+// WriteHeader records the status code and sends it.
+func (rr *ResponseRecorder) WriteHeader(code int) {
+	atomic.CompareAndSwapInt64(&rr.status, 0, int64(code))
+	rr.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the size of `p` and writes it to the response.
+func (rr *ResponseRecorder) Write(p []byte) (int, error) {
+	// Writing without a status code sends a 200.
+	atomic.CompareAndSwapInt64(&rr.status, 0, http.StatusOK)
+
+	n, err := rr.ResponseWriter.Write(p)
+	atomic.AddInt64(&rr.written, int64(n))
+	return n, err
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController.
+func (rr *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// recorderStatus is the lazy value of the `http_status` tag.
+type recorderStatus struct {
+	rr *ResponseRecorder
+}
+
+func (s recorderStatus) resolve() (interface{}, bool) {
+	status := s.rr.Status()
+	return status, status != 0
+}
+
+// recorderWritten is the lazy value of the `bytes_written` tag.
+type recorderWritten struct {
+	rr *ResponseRecorder
+}
+
+func (w recorderWritten) resolve() (interface{}, bool) {
+	return w.rr.BytesWritten(), w.rr.Status() != 0
+}
+
+// HTTPRequestOption configures the tags that WithHTTPRequest adds.
+type HTTPRequestOption func(*httpRequestConfig)
+
+type httpRequestConfig struct {
+	headers []string
+}
+
+// WithHTTPRequestHeaders also adds the given request headers, as tags named
+// like `http_header_x_request_id`. Headers which aren't set are skipped.
+func WithHTTPRequestHeaders(headers ...string) HTTPRequestOption {
+	return func(c *httpRequestConfig) {
+		c.headers = append(c.headers, headers...)
+	}
+}
+
+// WithHTTPRequest adds tags describing `req` to the context:
+// `http_method`, `http_path`, `http_host`, `http_proto`, `http_remote_addr`,
+// `http_user_agent` and `http_content_length`.
+func WithHTTPRequest(ctx context.Context, req *http.Request, opts ...HTTPRequestOption) context.Context {
+	var cfg httpRequestConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tags := []Tag{
+		{K: "http_method", V: req.Method, Override: true},
+		{K: "http_path", V: req.URL.Path, Override: true},
+		{K: "http_host", V: req.Host, Override: true},
+		{K: "http_proto", V: req.Proto, Override: true},
+		{K: "http_remote_addr", V: req.RemoteAddr, Override: true},
+		{K: "http_user_agent", V: req.UserAgent(), Override: true},
+		{K: "http_content_length", V: req.ContentLength, Override: true},
+	}
+	for _, h := range cfg.headers {
+		if v := req.Header.Get(h); v != "" {
+			tags = append(tags, Tag{K: headerTag(h), V: v, Override: true})
+		}
+	}
+
+	return registryFor(ctx).WithAll(ctx, tags...)
+}
+
+// headerTag returns the tag key for the HTTP header `h`.
+func headerTag(h string) string {
+	return "http_header_" + strings.ReplaceAll(strings.ToLower(h), "-", "_")
+}
+
+// WithHTTPResponse adds tags describing `resp`, like one returned by an
+// http.Client, to the context: `http_status`, `http_status_text`,
+// `http_response_content_type` and `http_response_content_length`.
+func WithHTTPResponse(ctx context.Context, resp *http.Response) context.Context {
+	return registryFor(ctx).WithAll(ctx,
+		Tag{K: "http_status", V: resp.StatusCode, Override: true},
+		Tag{K: "http_status_text", V: http.StatusText(resp.StatusCode), Override: true},
+		Tag{K: "http_response_content_type", V: resp.Header.Get("Content-Type"), Override: true},
+		Tag{K: "http_response_content_length", V: resp.ContentLength, Override: true},
+	)
+}
@@ -0,0 +1,142 @@
+package ctxlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// HTTPSink sends each entry as a JSON document in a POST request.
+type HTTPSink struct {
+	sinkCounters
+
+	net       netConfig
+	url       string
+	client    *http.Client
+	authorize func(*http.Request) error
+}
+
+// HTTPSinkOption configures an HTTPSink. Any SinkOption can be used as one.
+type HTTPSinkOption interface {
+	applyHTTP(*HTTPSink)
+}
+
+// httpSinkOption is an HTTPSinkOption that only applies to HTTP sinks.
+type httpSinkOption func(*HTTPSink)
+
+func (o httpSinkOption) applyHTTP(hs *HTTPSink) {
+	o(hs)
+}
+
+// WithOAuth2 authenticates requests with a bearer token from an OAuth2
+// client credentials flow. Tokens are fetched, cached and refreshed before
+// they expire as needed.
+func WithOAuth2(tokenURL, clientID, clientSecret string, scopes []string) HTTPSinkOption {
+	cfg := clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     tokenURL,
+		Scopes:       scopes,
+	}
+
+	return withTokenSource(cfg.TokenSource(context.Background()))
+}
+
+// WithStaticBearer authenticates requests with a bearer token that never
+// changes.
+func WithStaticBearer(token string) HTTPSinkOption {
+	return withTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
+}
+
+// WithBasicAuth authenticates requests with a username and password.
+func WithBasicAuth(user, pass string) HTTPSinkOption {
+	return httpSinkOption(func(hs *HTTPSink) {
+		hs.authorize = func(req *http.Request) error {
+			req.SetBasicAuth(user, pass)
+			return nil
+		}
+	})
+}
+
+func withTokenSource(ts oauth2.TokenSource) HTTPSinkOption {
+	return httpSinkOption(func(hs *HTTPSink) {
+		hs.authorize = func(req *http.Request) error {
+			tok, err := ts.Token()
+			if err != nil {
+				return fmt.Errorf("could not get token: %v", err)
+			}
+
+			tok.SetAuthHeader(req)
+			return nil
+		}
+	})
+}
+
+// NewHTTPSink creates a sink which posts entries to `url`.
+func NewHTTPSink(url string, opts ...HTTPSinkOption) *HTTPSink {
+	hs := &HTTPSink{url: url}
+	for _, opt := range opts {
+		opt.applyHTTP(hs)
+	}
+
+	hs.client = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: hs.net.transport(),
+	}
+	return hs
+}
+
+// Log posts the entry to the sink's URL.
+func (hs *HTTPSink) Log(entry LogEntry) error {
+	return hs.LogContext(context.Background(), entry)
+}
+
+// LogContext posts the entry to the sink's URL, giving up if `ctx` is done
+// first.
+func (hs *HTTPSink) LogContext(ctx context.Context, entry LogEntry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		hs.record(0, err)
+		return err
+	}
+
+	err = hs.post(ctx, body)
+	hs.record(len(body), err)
+	return err
+}
+
+func (hs *HTTPSink) post(ctx context.Context, body []byte) error {
+	if hs.net.err != nil {
+		return hs.net.err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hs.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if hs.authorize != nil {
+		if err := hs.authorize(req); err != nil {
+			return err
+		}
+	}
+
+	resp, err := hs.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response from %s: %s", hs.url, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package ctxlog
+
+import (
+	"github.com/fatih/color"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+// The levels that ctxlog knows how to log at, from least to most severe.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+// String returns the name that's printed alongside entries at this level.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelFatal:
+		return "FATAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// color returns the console color for this level.
+func (l Level) color() *color.Color {
+	switch l {
+	case LevelDebug:
+		return debugC
+	case LevelWarn:
+		return warnC
+	case LevelError:
+		return errC
+	case LevelFatal:
+		return fatalC
+	default:
+		return infoC
+	}
+}
+
+// valueColor returns the console color for tag values at this level, which
+// is the same hue as color but not bold.
+func (l Level) valueColor() *color.Color {
+	switch l {
+	case LevelDebug:
+		return debugValC
+	case LevelWarn:
+		return warnValC
+	case LevelError, LevelFatal:
+		return errValC
+	default:
+		return infoValC
+	}
+}
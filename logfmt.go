@@ -0,0 +1,103 @@
+package ctxlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// LogfmtFormatter writes entries as logfmt lines, like:
+//
+//	ts=2006-01-02T15:04:05Z level=INFO msg="hello world" user_id=123 instance_id=...
+type LogfmtFormatter struct{}
+
+// Format returns the entry as a single logfmt line, with a trailing newline.
+func (LogfmtFormatter) Format(entry LogEntry) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString("ts=")
+	buf.WriteString(entry.Time.Format(time.RFC3339Nano))
+	buf.WriteString(" level=")
+	buf.WriteString(entry.Level.String())
+	buf.WriteString(" msg=")
+	buf.WriteString(quoteLogfmt(entry.Message))
+
+	for _, t := range entry.Tags {
+		fmt.Fprintf(&buf, " %s=%s", t.K, logfmtValue(t.V))
+	}
+
+	buf.WriteString(" instance_id=")
+	buf.WriteString(entry.InstanceID)
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// logfmtValue formats a tag value, quoting it only if it needs it.
+func logfmtValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if !needsQuotes(s) {
+		return s
+	}
+
+	return quoteLogfmt(s)
+}
+
+// needsQuotes reports whether `s` can't be written as a bare logfmt value.
+func needsQuotes(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r >= utf8.RuneSelf {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quoteLogfmt returns `s` as a double-quoted string with JSON escaping.
+func quoteLogfmt(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	// Encoding a string can't fail.
+	enc.Encode(s)
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// LogfmtSink writes entries to a writer as logfmt lines.
+type LogfmtSink struct {
+	sinkCounters
+
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewLogfmtSink creates a sink that writes logfmt lines to `w`.
+func NewLogfmtSink(w io.Writer) *LogfmtSink {
+	return &LogfmtSink{w: w}
+}
+
+// Log writes the entry as a logfmt line.
+func (ls *LogfmtSink) Log(entry LogEntry) error {
+	line, err := LogfmtFormatter{}.Format(entry)
+	if err != nil {
+		ls.record(0, err)
+		return err
+	}
+
+	ls.mu.Lock()
+	n, err := ls.w.Write(line)
+	ls.mu.Unlock()
+
+	ls.record(n, err)
+	return err
+}
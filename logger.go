@@ -0,0 +1,41 @@
+package ctxlog
+
+import (
+	"context"
+)
+
+// Logger is what a context can carry to change where the package-level
+// functions log to. It's the same type as Registry, so a Registry made with
+// NewRegistry can be passed straight to ContextWithLogger.
+type Logger = Registry
+
+// loggerKey is the context key for a Logger carried by a context.
+type loggerKey struct{}
+
+// ContextWithLogger returns a context carrying `l`. The package-level
+// functions log through the Logger in a context, if it has one, rather
+// than the default, which allows logging to be customized per request.
+func ContextWithLogger(ctx context.Context, l *Logger) context.Context {
+	if lc, ok := ctx.(LoggingContext); ok {
+		lc.Context = context.WithValue(lc.Context, loggerKey{}, l)
+		return lc
+	}
+
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// LoggerFromContext returns the Logger carried by `ctx`, if it has one.
+func LoggerFromContext(ctx context.Context) (*Logger, bool) {
+	l, ok := ctx.Value(loggerKey{}).(*Logger)
+	return l, ok && l != nil
+}
+
+// registryFor returns the Registry that the package-level functions should
+// use for `ctx`.
+func registryFor(ctx context.Context) *Registry {
+	if l, ok := LoggerFromContext(ctx); ok {
+		return l
+	}
+
+	return std
+}
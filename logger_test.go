@@ -0,0 +1,43 @@
+package ctxlog
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextWithLogger(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(ctx context.Context)
+	}{
+		{"directly", func(ctx context.Context) { Infof(ctx, "hello") }},
+		{"after With", func(ctx context.Context) { Infof(With(ctx, "a", 1), "hello") }},
+		{"after Clone", func(ctx context.Context) { Infof(Clone(ctx), "hello") }},
+		{"inside Trace", func(ctx context.Context) {
+			Trace(ctx, "op", func(ctx context.Context) error {
+				Infof(ctx, "hello")
+				return nil
+			})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, sink := newRecordingRegistry()
+			ctx := ContextWithLogger(context.Background(), r)
+
+			if l, ok := LoggerFromContext(ctx); !ok || l != r {
+				t.Fatalf("LoggerFromContext returned %p, %v; want %p", l, ok, r)
+			}
+
+			tt.log(ctx)
+
+			found := false
+			for _, e := range sink.Entries() {
+				found = found || e.Message == "hello"
+			}
+			if !found {
+				t.Errorf("entry wasn't logged through the context's Logger: %v", sink.Entries())
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package ctxlog
+
+// Metrics receives counts of what a Registry logs, for exporting to a
+// monitoring system. See the metrics sub-packages for implementations.
+type Metrics interface {
+	// EntryLogged is called once for every entry that's logged.
+	EntryLogged(level Level)
+	// SinkError is called every time a sink fails to log an entry, including
+	// sinks that only get entries through RegisterRoute.
+	SinkError(sink string)
+}
+
+// DropMetrics can be implemented by Metrics that also want to count entries
+// which were logged, but never made it to the sinks.
+type DropMetrics interface {
+	// EntryDropped is called when an entry is thrown away, with a short
+	// reason like "middleware".
+	EntryDropped(reason string)
+}
+
+// UseMetrics starts counting log entries and sink errors in `m`.
+func UseMetrics(m Metrics) {
+	std.UseMetrics(m)
+}
+
+// UseMetrics starts counting the log entries and sink errors of this
+// Registry in `m`.
+func (r *Registry) UseMetrics(m Metrics) {
+	r.AddMiddleware(func(entry LogEntry, next func(LogEntry) error) error {
+		m.EntryLogged(entry.Level)
+		return next(entry)
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics = append(r.metrics, m)
+}
+
+// dropped tells each of the Metrics that can count them that an entry was
+// dropped for `reason`.
+func (p pipeline) dropped(reason string) {
+	for _, m := range p.metrics {
+		if dm, ok := m.(DropMetrics); ok {
+			dm.EntryDropped(reason)
+		}
+	}
+}
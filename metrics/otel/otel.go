@@ -0,0 +1,75 @@
+// Package otel counts ctxlog entries, sink errors and dropped entries with
+// OpenTelemetry metrics, so they show up alongside the rest of an
+// application's metrics.
+package otel
+
+import (
+	"context"
+
+	otelapi "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// OTelMetrics records ctxlog's counts with OpenTelemetry counters:
+//
+//	ctxlog.log_entries{level="INFO"}
+//	ctxlog.sink_errors{sink="console"}
+//	ctxlog.dropped_entries{reason="middleware"}
+type OTelMetrics struct {
+	entries    metric.Int64Counter
+	sinkErrors metric.Int64Counter
+	dropped    metric.Int64Counter
+}
+
+// NewOTelMetrics creates the counters from a meter provided by `mp`. Pass
+// the result to ctxlog.UseMetrics (or Registry.UseMetrics) to start counting.
+// Errors creating the counters go to OpenTelemetry's error handler, like
+// errors recording them do.
+func NewOTelMetrics(mp metric.MeterProvider) *OTelMetrics {
+	meter := mp.Meter("github.com/silversupreme/ctxlog")
+
+	return &OTelMetrics{
+		entries: counter(meter, "ctxlog.log_entries",
+			"Number of log entries, by level."),
+		sinkErrors: counter(meter, "ctxlog.sink_errors",
+			"Number of times a sink failed to log an entry, by sink name."),
+		dropped: counter(meter, "ctxlog.dropped_entries",
+			"Number of log entries that never reached the sinks, by reason."),
+	}
+}
+
+// counter creates a counter called `name`. If the meter can't create it,
+// the error is handed to otel.Handle, and the counter does nothing.
+func counter(meter metric.Meter, name, desc string) metric.Int64Counter {
+	c, err := meter.Int64Counter(name, metric.WithDescription(desc))
+	if err != nil {
+		otelapi.Handle(err)
+	}
+	if c == nil {
+		return noop.Int64Counter{}
+	}
+
+	return c
+}
+
+// EntryLogged counts an entry at `level`.
+func (m *OTelMetrics) EntryLogged(level ctxlog.Level) {
+	m.entries.Add(context.Background(), 1,
+		metric.WithAttributes(attribute.String("level", level.String())))
+}
+
+// SinkError counts a failure of the sink named `sink`.
+func (m *OTelMetrics) SinkError(sink string) {
+	m.sinkErrors.Add(context.Background(), 1,
+		metric.WithAttributes(attribute.String("sink", sink)))
+}
+
+// EntryDropped counts an entry that was dropped for `reason`.
+func (m *OTelMetrics) EntryDropped(reason string) {
+	m.dropped.Add(context.Background(), 1,
+		metric.WithAttributes(attribute.String("reason", reason)))
+}
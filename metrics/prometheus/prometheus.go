@@ -0,0 +1,47 @@
+// Package prometheus counts ctxlog entries and sink errors as Prometheus
+// metrics. Nothing is registered unless this package is imported and used.
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// PrometheusMetrics exports ctxlog's counts as Prometheus counters:
+//
+//	ctxlog_log_entries_total{level="INFO"}
+//	ctxlog_sink_errors_total{sink="console"}
+type PrometheusMetrics struct {
+	entries    *prom.CounterVec
+	sinkErrors *prom.CounterVec
+}
+
+// NewPrometheusMetrics creates the counters and registers them with `reg`.
+// Pass the result to ctxlog.UseMetrics (or Registry.UseMetrics) to start
+// counting.
+func NewPrometheusMetrics(reg prom.Registerer) *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		entries: prom.NewCounterVec(prom.CounterOpts{
+			Name: "ctxlog_log_entries_total",
+			Help: "Number of log entries, by level.",
+		}, []string{"level"}),
+		sinkErrors: prom.NewCounterVec(prom.CounterOpts{
+			Name: "ctxlog_sink_errors_total",
+			Help: "Number of times a sink failed to log an entry, by sink name.",
+		}, []string{"sink"}),
+	}
+
+	reg.MustRegister(m.entries, m.sinkErrors)
+	return m
+}
+
+// EntryLogged counts an entry at `level`.
+func (m *PrometheusMetrics) EntryLogged(level ctxlog.Level) {
+	m.entries.WithLabelValues(level.String()).Inc()
+}
+
+// SinkError counts a failure of the sink named `sink`.
+func (m *PrometheusMetrics) SinkError(sink string) {
+	m.sinkErrors.WithLabelValues(sink).Inc()
+}
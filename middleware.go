@@ -0,0 +1,35 @@
+package ctxlog
+
+// Middleware transforms an entry on its way to the sinks. It should call
+// `next` with the (possibly changed) entry to pass it on, or return without
+// calling it to drop the entry.
+type Middleware func(entry LogEntry, next func(LogEntry) error) error
+
+// AddMiddleware adds a transform to the pipeline between the logging call and
+// the sinks. Middlewares run in the order they were added.
+func AddMiddleware(fn Middleware) {
+	std.AddMiddleware(fn)
+}
+
+// AddMiddleware adds a transform to the pipeline between the logging call and
+// the sinks of this Registry. Middlewares run in the order they were added.
+func (r *Registry) AddMiddleware(fn Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.middleware = append(r.middleware, fn)
+}
+
+// chain wraps `last` in all of the middleware, so that the first one added
+// is the first one called.
+func (p pipeline) chain(last func(LogEntry) error) func(LogEntry) error {
+	next := last
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		mw, inner := p.middleware[i], next
+		next = func(e LogEntry) error {
+			return mw(e, inner)
+		}
+	}
+
+	return next
+}
@@ -0,0 +1,67 @@
+package ctxlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var order []string
+	named := func(name string) Middleware {
+		return func(e LogEntry, next func(LogEntry) error) error {
+			order = append(order, name)
+			return next(e)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		middleware  []Middleware
+		wantMessage string
+		wantOrder   []string
+		wantConsole string
+	}{
+		{"none", nil, "hello", nil, ""},
+		{"in order", []Middleware{named("a"), named("b"), named("c")}, "hello", []string{"a", "b", "c"}, ""},
+		{"transform", []Middleware{func(e LogEntry, next func(LogEntry) error) error {
+			e.Message = strings.ToUpper(e.Message)
+			return next(e)
+		}}, "HELLO", nil, ""},
+		{"drop", []Middleware{named("a"), func(LogEntry, func(LogEntry) error) error {
+			return nil
+		}, named("c")}, "", []string{"a"}, ""},
+		{"error", []Middleware{func(LogEntry, func(LogEntry) error) error {
+			return errors.New("bad entry")
+		}}, "", nil, "Could not process log middleware: bad entry"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order = nil
+			r, sink := newRecordingRegistry()
+			console := &bytes.Buffer{}
+			r.console.out = console
+			for _, mw := range tt.middleware {
+				r.AddMiddleware(mw)
+			}
+
+			r.Infof(context.Background(), "hello")
+
+			entries := sink.Entries()
+			switch {
+			case tt.wantMessage == "" && len(entries) != 0:
+				t.Errorf("logged %v, want it dropped", entries)
+			case tt.wantMessage != "" && (len(entries) != 1 || entries[0].Message != tt.wantMessage):
+				t.Errorf("logged %v, want %q", entries, tt.wantMessage)
+			}
+			if strings.Join(order, ",") != strings.Join(tt.wantOrder, ",") {
+				t.Errorf("middleware ran in order %v, want %v", order, tt.wantOrder)
+			}
+			if !strings.Contains(console.String(), tt.wantConsole) {
+				t.Errorf("console has %q, want %q", console.String(), tt.wantConsole)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package ctxlog
+
+import (
+	"context"
+)
+
+// WithNamespace returns a context where the keys of any tags added with With
+// or WithAll are prefixed with `ns` and a dot, so that different parts of a
+// program can't overwrite each other's tags. Namespaces nest.
+func WithNamespace(ctx context.Context, ns string) context.Context {
+	return registryFor(ctx).WithNamespace(ctx, ns)
+}
+
+// WithNS adds the tag `ns.k` to the context.
+func WithNS(ctx context.Context, ns, k string, v interface{}) context.Context {
+	return registryFor(ctx).WithNS(ctx, ns, k, v)
+}
+
+// WithAllUnnamespaced is like WithAll, but leaves the keys of `tags` as they
+// are even inside a namespace. It's meant for keys with a fixed meaning
+// outside of the program, like OpenTelemetry's attribute names, which
+// dashboards and alerts look for by name.
+func WithAllUnnamespaced(ctx context.Context, tags ...Tag) context.Context {
+	return registryFor(ctx).WithAllUnnamespaced(ctx, tags...)
+}
+
+// WithAllUnnamespaced is like WithAll, but leaves the keys of `tags` as they
+// are even inside a namespace.
+func (r *Registry) WithAllUnnamespaced(ctx context.Context, tags ...Tag) context.Context {
+	if len(tags) == 0 {
+		return ctx
+	}
+
+	return r.withAll(ctx, r.validated(r.withReplacements(ctx, r.normalized(tags)))...)
+}
+
+// WithNamespace returns a context where the keys of any tags added with With
+// or WithAll are prefixed with `ns` and a dot.
+func (r *Registry) WithNamespace(ctx context.Context, ns string) context.Context {
+	lc, ok := r.withAll(ctx).(LoggingContext)
+	if !ok {
+		return ctx
+	}
+
+	if lc.ns != "" {
+		ns = lc.ns + "." + ns
+	}
+	lc.ns = ns
+
+	return lc
+}
+
+// WithNS adds the tag `ns.k` to the context.
+func (r *Registry) WithNS(ctx context.Context, ns, k string, v interface{}) context.Context {
+	return r.WithAll(ctx, Tag{K: ns + "." + k, V: v})
+}
+
+// WithGroup is WithNamespace under the name used by log/slog: tags added to
+// the returned context are put under `group`.
+func WithGroup(ctx context.Context, group string) context.Context {
+	return registryFor(ctx).WithNamespace(ctx, group)
+}
+
+// EndGroup closes the innermost group or namespace of `ctx`, so that tags
+// added to the returned context go where they did before it was opened.
+// Tags already added under the group keep their keys.
+func EndGroup(ctx context.Context) context.Context {
+	lc, ok := ctx.(LoggingContext)
+	if !ok || lc.ns == "" {
+		return ctx
+	}
+
+	lc.ns = namespaceOf(lc.ns)
+	return lc
+}
+
+// WithGroupFunc calls `fn` with a context where tags are put under `group`,
+// and returns the context it returns with the group closed again.
+//
+//	ctx = ctxlog.WithGroupFunc(ctx, "db", func(ctx context.Context) context.Context {
+//		return ctxlog.With(ctx, "host", host) // db.host
+//	})
+func WithGroupFunc(ctx context.Context, group string, fn func(context.Context) context.Context) context.Context {
+	ns := ""
+	if lc, ok := ctx.(LoggingContext); ok {
+		ns = lc.ns
+	}
+
+	ret := fn(WithGroup(ctx, group))
+	if lc, ok := ret.(LoggingContext); ok {
+		lc.ns = ns
+		return lc
+	}
+
+	return ret
+}
+
+// namespaced returns the key that With and WithAll store `k` under in `ctx`,
+// with the context's namespace in front of it.
+func namespaced(ctx context.Context, k string) string {
+	if lc, ok := ctx.(LoggingContext); ok && lc.ns != "" {
+		return lc.ns + "." + k
+	}
+
+	return k
+}
+
+// namespaceOf returns the namespace part of a tag key, if it has one.
+func namespaceOf(k string) string {
+	for i := len(k) - 1; i >= 0; i-- {
+		if k[i] == '.' {
+			return k[:i]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,64 @@
+package ctxlog
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TagNormalizer rewrites the value of a tag before it's stored in a context.
+type TagNormalizer func(key string, value interface{}) interface{}
+
+// SetTagNormalization makes every tag value added to a context with With,
+// WithAll or WithAllUnnamespaced go through `fn` first, so that the same
+// value is always logged the same way. Values added with WithTyped are left
+// as they are so that GetTyped can read them back, as are the tags ctxlog
+// adds itself, like span_id, and values that aren't known until the entry
+// is logged. Pass nil to turn it off again.
+func SetTagNormalization(fn func(key string, value interface{}) interface{}) {
+	std.SetTagNormalization(fn)
+}
+
+// SetTagNormalization makes every tag value added through this Registry's
+// With, WithAll or WithAllUnnamespaced go through `fn` first.
+func (r *Registry) SetTagNormalization(fn func(key string, value interface{}) interface{}) {
+	r.normalize.Store(TagNormalizer(fn))
+}
+
+// normalized returns `tags` with their values normalized, if that's turned
+// on. The caller's slice isn't changed.
+func (r *Registry) normalized(tags []Tag) []Tag {
+	fn, _ := r.normalize.Load().(TagNormalizer)
+	if fn == nil {
+		return tags
+	}
+
+	ret := make([]Tag, len(tags))
+	for i, t := range tags {
+		if _, lazy := t.V.(lazyValue); !lazy {
+			t.V = fn(t.K, t.V)
+		}
+		ret[i] = t
+	}
+
+	return ret
+}
+
+// StrictJSONNormalization is a normalizer for SetTagNormalization that
+// makes values look the same whatever type they were added as: numbers
+// become float64, like JSON numbers, and other primitive values like bools
+// become strings. Strings and composite values are left as they are.
+func StrictJSONNormalization(key string, value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool, reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(value)
+	default:
+		return value
+	}
+}
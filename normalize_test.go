@@ -0,0 +1,50 @@
+package ctxlog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestSetTagNormalization(t *testing.T) {
+	tests := []struct {
+		name      string
+		normalize func(key string, value interface{}) interface{}
+		add       func(r *Registry, ctx context.Context) context.Context
+		want      interface{}
+	}{
+		{"off", nil, func(r *Registry, ctx context.Context) context.Context {
+			return r.With(ctx, "v", 42)
+		}, []interface{}{42}},
+		{"int", StrictJSONNormalization, func(r *Registry, ctx context.Context) context.Context {
+			return r.With(ctx, "v", 42)
+		}, []interface{}{float64(42)}},
+		{"uint", StrictJSONNormalization, func(r *Registry, ctx context.Context) context.Context {
+			return r.With(ctx, "v", uint8(7))
+		}, []interface{}{float64(7)}},
+		{"bool", StrictJSONNormalization, func(r *Registry, ctx context.Context) context.Context {
+			return r.With(ctx, "v", true)
+		}, []interface{}{"true"}},
+		{"string", StrictJSONNormalization, func(r *Registry, ctx context.Context) context.Context {
+			return r.With(ctx, "v", "42")
+		}, []interface{}{"42"}},
+		{"WithAllUnnamespaced", StrictJSONNormalization, func(r *Registry, ctx context.Context) context.Context {
+			return r.WithAllUnnamespaced(ctx, Tag{K: "v", V: 42})
+		}, []interface{}{float64(42)}},
+		{"WithTyped", StrictJSONNormalization, func(r *Registry, ctx context.Context) context.Context {
+			return WithTyped(ContextWithLogger(ctx, r), "v", 42)
+		}, []interface{}{42}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.SetTagNormalization(tt.normalize)
+
+			ctx := tt.add(r, context.Background())
+			got, _ := tagValues(ctx, "v")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stored %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync/atomic"
+)
+
+// maxOnceMessages is how many messages DebugfOnce remembers. Tags like
+// request IDs make most messages unique, so once there are this many, it
+// forgets them all and starts again rather than growing forever.
+const maxOnceMessages = 10000
+
+// DebugfOnce is like Debugf, but only logs each message once: later calls
+// with the same formatted message and the same tags are dropped. It's for
+// loops and hot paths that would otherwise log the same line over and
+// over. It remembers up to 10000 messages; after that it starts again, so a
+// message may be logged once more.
+func DebugfOnce(ctx context.Context, msg string, args ...interface{}) {
+	registryFor(ctx).DebugfOnce(ctx, msg, args...)
+}
+
+// ResetOnce forgets the messages that DebugfOnce has logged, so that they're
+// logged again.
+func ResetOnce() {
+	std.ResetOnce()
+}
+
+// DebugfOnce is like Debugf, but only logs each message once through this
+// Registry.
+func (r *Registry) DebugfOnce(ctx context.Context, msg string, args ...interface{}) {
+	if !r.enabled(LevelDebug) {
+		return
+	}
+
+	formatted := fmt.Sprintf(msg, args...)
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d\x00%s", ContextFingerprint(ctx), formatted)
+
+	if _, seen := r.seen.LoadOrStore(h.Sum64(), struct{}{}); seen {
+		return
+	}
+	if atomic.AddInt64(&r.seenCount, 1) > maxOnceMessages {
+		r.ResetOnce()
+	}
+
+	r.logf(ctx, LevelDebug, "%s", formatted)
+}
+
+// ResetOnce forgets the messages that DebugfOnce has logged through this
+// Registry.
+func (r *Registry) ResetOnce() {
+	atomic.StoreInt64(&r.seenCount, 0)
+	r.seen.Range(func(k, _ interface{}) bool {
+		r.seen.Delete(k)
+		return true
+	})
+}
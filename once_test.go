@@ -0,0 +1,70 @@
+package ctxlog
+
+import (
+	"context"
+	"testing"
+)
+
+// countingSink counts the entries it's given.
+type countingSink struct{ n int }
+
+func (cs *countingSink) Log(LogEntry) error {
+	cs.n++
+	return nil
+}
+
+func TestDebugfOnce(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(r *Registry, ctx context.Context)
+		want int
+	}{
+		{"same message", func(r *Registry, ctx context.Context) {
+			r.DebugfOnce(ctx, "hello %d", 1)
+			r.DebugfOnce(ctx, "hello %d", 1)
+		}, 1},
+		{"different messages", func(r *Registry, ctx context.Context) {
+			r.DebugfOnce(ctx, "hello %d", 1)
+			r.DebugfOnce(ctx, "hello %d", 2)
+		}, 2},
+		{"different tags", func(r *Registry, ctx context.Context) {
+			r.DebugfOnce(r.With(ctx, "a", 1), "hello")
+			r.DebugfOnce(r.With(ctx, "a", 2), "hello")
+		}, 2},
+		{"after reset", func(r *Registry, ctx context.Context) {
+			r.DebugfOnce(ctx, "hello")
+			r.ResetOnce()
+			r.DebugfOnce(ctx, "hello")
+		}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(WithDebug(true))
+			sink := &countingSink{}
+			r.UseSink("console", sink)
+
+			tt.log(r, context.Background())
+			if sink.n != tt.want {
+				t.Errorf("logged %d entries, want %d", sink.n, tt.want)
+			}
+		})
+	}
+}
+
+func TestDebugfOnceIsBounded(t *testing.T) {
+	r := NewRegistry(WithDebug(true))
+	r.UseSink("console", NoopSink{})
+
+	for i := 0; i < 3*maxOnceMessages; i++ {
+		r.DebugfOnce(r.With(context.Background(), "request_id", i), "hello")
+	}
+
+	n := 0
+	r.seen.Range(func(_, _ interface{}) bool {
+		n++
+		return true
+	})
+	if n > maxOnceMessages {
+		t.Errorf("remembering %d messages, want at most %d", n, maxOnceMessages)
+	}
+}
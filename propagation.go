@@ -0,0 +1,84 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+)
+
+// Propagate marks tags which should be passed on to other services, along
+// with the trace and span IDs, when a context is sent across a process
+// boundary. It applies to the default Registry; contexts carrying their own
+// use the keys marked with its Propagate method.
+func Propagate(keys ...string) {
+	std.Propagate(keys...)
+}
+
+// PropagationFields returns the parts of a context that should be passed on
+// to another service: `trace_id`, `span_id`, `instance_id`, and any tags
+// marked with Propagate. Multi-valued tags only send their latest value.
+func PropagationFields(ctx context.Context) map[string]string {
+	return registryFor(ctx).PropagationFields(ctx)
+}
+
+// FromPropagation adds fields received from another service, as returned by
+// PropagationFields, to a context. Only `trace_id`, `span_id` and the keys
+// marked with Propagate are taken, so that a sender can't overwrite other
+// tags; the rest are ignored. The sender's `instance_id` is tagged as
+// `remote_instance_id`, so that it doesn't get confused with our own.
+func FromPropagation(ctx context.Context, fields map[string]string) context.Context {
+	return registryFor(ctx).FromPropagation(ctx, fields)
+}
+
+// Propagate marks tags which should be passed on to other services.
+func (r *Registry) Propagate(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.propagate = append(r.propagate, keys...)
+}
+
+// PropagationFields returns the parts of a context that should be passed on
+// to another service.
+func (r *Registry) PropagationFields(ctx context.Context) map[string]string {
+	keys := r.propagatedKeys()
+
+	ret := map[string]string{
+		"instance_id": r.id.String(),
+	}
+	for _, k := range keys {
+		if vals, ok := tagValues(ctx, k); ok && len(vals) > 0 {
+			ret[k] = fmt.Sprint(vals[len(vals)-1])
+		}
+	}
+
+	return ret
+}
+
+// FromPropagation adds fields received from another service to a context.
+func (r *Registry) FromPropagation(ctx context.Context, fields map[string]string) context.Context {
+	keys := append(r.propagatedKeys(), "instance_id")
+
+	tags := make([]Tag, 0, len(keys))
+	for _, k := range keys {
+		v, ok := fields[k]
+		if !ok {
+			continue
+		}
+
+		if k == "instance_id" {
+			k = "remote_instance_id"
+		}
+		tags = append(tags, Tag{K: k, V: v, Override: true})
+	}
+
+	return r.withAll(ctx, tags...)
+}
+
+// propagatedKeys returns the keys of the tags that are passed between
+// services.
+func (r *Registry) propagatedKeys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string{"trace_id", "span_id"}, r.propagate...)
+}
@@ -0,0 +1,560 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Registry holds everything ctxlog needs to emit logs: the sinks that receive
+// entries, the instance ID attached to each of them, and whether debug logs
+// are turned on. The package-level functions use a default Registry; create
+// your own with NewRegistry when you need logging that doesn't interfere
+// with the rest of the program, like in parallel tests or multi-tenant
+// servers.
+type Registry struct {
+	mu    sync.RWMutex
+	debug *bool
+
+	// Keep the ConsoleSink around as a backup in case other sinks fail.
+	// The sinks, routes, middleware, metrics and hooks are only ever replaced,
+	// never changed in place, so that logf can use them without holding mu.
+	console    *ConsoleSink
+	sinks      map[string]Sink
+	middleware []Middleware
+	metrics    []Metrics
+
+	// Extra places that entries matching a condition are sent to.
+	routes     []route
+	routeSinks map[string]Sink
+
+	// Tags which are passed on to other services.
+	propagate []string
+
+	// Tag keys which are also logged under another name.
+	aliases map[string]string
+
+	// Tag keys which are being renamed, and what to.
+	deprecated map[string]string
+
+	// Positions of the arguments of each query which WithSQL must redact.
+	sensitiveSQL map[string]map[int]bool
+
+	// The TagNormalizer that tag values go through, if any. It's read on
+	// every With, so it's kept out of mu.
+	normalize atomic.Value
+
+	// Tags which are added to every entry.
+	globals []Tag
+
+	// Whether tags with zero values are left out of entries.
+	omitEmpty bool
+
+	// Hashes of the messages that DebugfOnce has logged, and how many.
+	seen      sync.Map
+	seenCount int64
+
+	// The unit that span durations are logged in.
+	durUnit time.Duration
+
+	// How many spans are running from each parent context, by fingerprint.
+	// Only kept while debugging.
+	spansMu       sync.Mutex
+	activeParents map[uint64]int
+
+	// How many sinks can handle an entry at once; 0 means all of them.
+	concurrency int
+
+	preHooks  []PreHook
+	postHooks []PostHook
+
+	// The logging context will always include a random UUID which is tagged
+	// to uniquely identify this particular version/invocation of this program.
+	// Allows us to see when restarts happen/induce changes in behaviour.
+	id uuid.UUID
+}
+
+// RegistryOption configures a Registry created with NewRegistry.
+type RegistryOption func(*Registry)
+
+// WithDebug turns debug logging on or off for a Registry.
+func WithDebug(enabled bool) RegistryOption {
+	return func(r *Registry) {
+		r.debug = &enabled
+	}
+}
+
+// WithInstanceID sets the ID which is attached to every entry logged through
+// a Registry, in place of a random one.
+func WithInstanceID(id uuid.UUID) RegistryOption {
+	return func(r *Registry) {
+		r.id = id
+	}
+}
+
+// WithOmitEmpty leaves tags whose value is the zero value for its type, like
+// "", 0, false or nil, out of the entries logged through a Registry. They're
+// still kept in the context, so they can be read back or replaced.
+func WithOmitEmpty(enabled bool) RegistryOption {
+	return func(r *Registry) {
+		r.omitEmpty = enabled
+	}
+}
+
+// NewRegistry creates a Registry that logs to the console, and shares no
+// state with the package-level functions or any other Registry.
+func NewRegistry(opts ...RegistryOption) *Registry {
+	r := newRegistry(new(bool))
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+func newRegistry(debug *bool) *Registry {
+	r := &Registry{
+		debug:       debug,
+		concurrency: 1,
+		durUnit:     time.Millisecond,
+	}
+	r.console = &ConsoleSink{}
+	r.sinks = map[string]Sink{
+		"console": r.console,
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		r.id = uuid.Nil
+		r.fallback(context.Background(), "Could not create a unique ID for this application: %v", err)
+	} else {
+		r.id = id
+	}
+	r.detectService()
+
+	return r
+}
+
+// enabled reports whether entries at the given level should be logged at all.
+func (r *Registry) enabled(level Level) bool {
+	return level != LevelDebug || *r.debug
+}
+
+// UseSink adds a sink which will receive all logs output through this Registry.
+func (r *Registry) UseSink(name string, s Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Replacing the console is how it's silenced, so that's expected.
+	if _, exists := r.sinks[name]; exists && debugChecks && name != "console" {
+		r.fallback(context.Background(), "Sink '%s' was registered more than once; replacing it", name)
+	}
+	r.sinks = withSink(r.sinks, name, s)
+	attach(s, r)
+}
+
+// withSink returns a copy of `sinks` with `s` added under `name`, or with
+// `name` removed if `s` is nil.
+func withSink(sinks map[string]Sink, name string, s Sink) map[string]Sink {
+	ret := make(map[string]Sink, len(sinks)+1)
+	for n, sink := range sinks {
+		ret[n] = sink
+	}
+
+	if s == nil {
+		delete(ret, name)
+	} else {
+		ret[name] = s
+	}
+
+	return ret
+}
+
+// LookupSink returns the sink registered under `name` in this Registry, if
+// there is one.
+func (r *Registry) LookupSink(name string) (Sink, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.sinks[name]
+	return s, ok
+}
+
+// RemoveSink stops the sink registered under `name` from receiving logs
+// output through this Registry.
+func (r *Registry) RemoveSink(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sinks = withSink(r.sinks, name, nil)
+}
+
+// With adds a tag to the context, which is carried into subsequent logging calls.
+func (r *Registry) With(ctx context.Context, k string, v interface{}) context.Context {
+	return r.WithAll(ctx, Tag{K: k, V: v})
+}
+
+// WithAll adds multiple tags at once to a context, which avoids a ton of
+// GC churn when you know you have multiple things to add to a logging
+// statement.
+func (r *Registry) WithAll(ctx context.Context, tags ...Tag) context.Context {
+	return r.withNamespaced(ctx, r.normalized(tags))
+}
+
+// withNamespaced is WithAll without normalization.
+func (r *Registry) withNamespaced(ctx context.Context, tags []Tag) context.Context {
+	// Nothing to add, so there's no need to copy anything either.
+	if len(tags) == 0 {
+		return ctx
+	}
+
+	tags = r.withReplacements(ctx, tags)
+
+	if lc, ok := ctx.(LoggingContext); ok && lc.ns != "" {
+		prefixed := make([]Tag, len(tags))
+		for i, t := range tags {
+			t.K = namespaced(lc, t.K)
+			prefixed[i] = t
+		}
+		tags = prefixed
+	}
+
+	return r.withAll(ctx, r.validated(tags)...)
+}
+
+// validated returns `tags` with a `tag_key_error` tag for each invalid key,
+// while debugging.
+func (r *Registry) validated(tags []Tag) []Tag {
+	// Checking every key isn't free, so only do it while debugging.
+	if debugChecks || r.enabled(LevelDebug) {
+		if errs := validateTags(tags); errs != nil {
+			tags = append(append([]Tag(nil), tags...), errs...)
+		}
+	}
+
+	return tags
+}
+
+// withAll is WithAll without the namespace, for tags whose keys ctxlog
+// itself depends on.
+func (r *Registry) withAll(ctx context.Context, tags ...Tag) context.Context {
+	ret := LoggingContext{}
+
+	switch ctx.(type) {
+	case LoggingContext:
+		lc := ctx.(LoggingContext)
+		ret.Context = lc.Context
+		ret.ns = lc.ns
+		ret.tags = lc.tags
+	default:
+		ret.Context = ctx
+	}
+
+	// The new tags are added on top of the existing ones without copying
+	// them, so that downstream functions can't overwrite or add to the tag
+	// set for a given context. Every LoggingContext gets a set, even an
+	// empty one, so that AppendToTrace has somewhere to put its tags.
+	switch {
+	case len(tags) > 0:
+		ret.tags = ret.tags.child(tags)
+	case ret.tags == nil:
+		ret.tags = newTagSet(nil, nil)
+	}
+
+	return ret
+}
+
+// WithValue is a hack to support adding WithValue to contexts without losing
+// logging information.
+func (r *Registry) WithValue(parent context.Context, k string, v interface{}) context.Context {
+	return r.ContextValue(parent, k, v)
+}
+
+// ContextValue is like context.WithValue, but always returns a
+// LoggingContext, keeping any tags that `parent` has.
+func (r *Registry) ContextValue(parent context.Context, key, val interface{}) context.Context {
+	lc, ok := parent.(LoggingContext)
+	if !ok {
+		lc = LoggingContext{Context: parent, tags: newTagSet(nil, nil)}
+	}
+
+	// context.WithValue panics on these, which isn't worth crashing over.
+	if key == nil || !reflect.TypeOf(key).Comparable() {
+		r.fallback(parent, "Could not add context value: key %#v is nil or not comparable", key)
+		return lc
+	}
+
+	lc.Context = context.WithValue(lc.Context, key, val)
+	return lc
+}
+
+// Clone creates a copy of `source` with all of the tags intact. The copy
+// isn't cancelled with `source`, and only carries over the Registry from its
+// values.
+func (r *Registry) Clone(source context.Context) context.Context {
+	ctx := context.Background()
+	if l, ok := LoggerFromContext(source); ok {
+		ctx = ContextWithLogger(ctx, l)
+	}
+
+	switch source.(type) {
+	case LoggingContext:
+		lc := source.(LoggingContext)
+		return LoggingContext{
+			Context: ctx,
+			tags:    newTagSet(lc.tags.clone()),
+			ns:      lc.ns,
+		}
+	default:
+		return LoggingContext{
+			Context: ctx,
+			tags:    newTagSet(nil, nil),
+		}
+	}
+}
+
+// WithContext returns a context that keeps the deadline, cancellation and
+// values of `parent`, but also carries the tags from `donor`. Tags that are in
+// both take their values from `donor`.
+func (r *Registry) WithContext(parent, donor context.Context) context.Context {
+	dc, ok := donor.(LoggingContext)
+	if !ok {
+		return parent
+	}
+
+	vals, order := dc.tags.load()
+	tags := make([]Tag, 0, len(order))
+	for _, k := range order {
+		for i, v := range vals[k] {
+			tags = append(tags, Tag{K: k, V: v, Override: i == 0})
+		}
+	}
+
+	return r.withAll(parent, tags...)
+}
+
+// pipeline is everything an entry goes through once it's built, as it was
+// when the entry was logged.
+type pipeline struct {
+	sinks       map[string]Sink
+	routes      []route
+	routeSinks  map[string]Sink
+	middleware  []Middleware
+	metrics     []Metrics
+	preHooks    []PreHook
+	postHooks   []PostHook
+	concurrency int
+}
+
+// pipeline returns the Registry's current pipeline. Callers must hold r.mu.
+func (r *Registry) pipeline() pipeline {
+	return pipeline{
+		sinks:       r.sinks,
+		routes:      r.routes,
+		routeSinks:  r.routeSinks,
+		middleware:  r.middleware,
+		metrics:     r.metrics,
+		preHooks:    r.preHooks,
+		postHooks:   r.postHooks,
+		concurrency: r.concurrency,
+	}
+}
+
+func (r *Registry) logf(ctx context.Context, level Level, msg string, args ...interface{}) {
+	// Hooks, middleware and sinks run without the lock held, so that they
+	// can log through this Registry or change it themselves.
+	r.mu.RLock()
+	entry := r.applyAliases(r.applyGlobals(r.newEntry(ctx, level, msg, args...)))
+	p := r.pipeline()
+	r.mu.RUnlock()
+
+	for _, hook := range p.preHooks {
+		if !hook(ctx, &entry) {
+			p.dropped("hook")
+			return
+		}
+	}
+
+	var errs []error
+	delivered := false
+	deliver := func(e LogEntry) error {
+		entry, delivered = e, true
+		errs = append(r.deliver(ctx, p, e), r.route(ctx, p, e)...)
+		return nil
+	}
+
+	if err := p.chain(deliver)(entry); err != nil {
+		r.fallback(ctx, "Could not process log middleware: %v", err)
+	}
+
+	if !delivered {
+		p.dropped("middleware")
+		return
+	}
+
+	for _, hook := range p.postHooks {
+		hook(ctx, entry, errs)
+	}
+}
+
+// deliver hands the entry to each sink, and returns the errors they had.
+func (r *Registry) deliver(ctx context.Context, p pipeline, entry LogEntry) []error {
+	var errs []error
+	failed := func(name string, err error) {
+		errs = append(errs, err)
+		r.sinkFailed(ctx, p, name, err)
+	}
+
+	if p.concurrency == 1 || len(p.sinks) < 2 {
+		for name, sink := range p.sinks {
+			if err := r.logTo(ctx, name, sink, entry); err != nil {
+				failed(name, err)
+			}
+		}
+
+		return errs
+	}
+
+	// Errors are only reported once every sink is done, so that the console
+	// isn't written to from several goroutines at once.
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		sinkErr = map[string]error{}
+		sem     chan struct{}
+	)
+	if p.concurrency > 0 {
+		sem = make(chan struct{}, p.concurrency)
+	}
+
+	for name, sink := range p.sinks {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+
+		wg.Add(1)
+		go func(name string, sink Sink) {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+
+			if err := r.logTo(ctx, name, sink, entry); err != nil {
+				mu.Lock()
+				sinkErr[name] = err
+				mu.Unlock()
+			}
+		}(name, sink)
+	}
+	wg.Wait()
+
+	for name, err := range sinkErr {
+		failed(name, err)
+	}
+
+	return errs
+}
+
+// sinkFailed counts an error from the sink `name` in the metrics, and reports
+// it to the console. Both the usual sinks and route sinks go through here.
+func (r *Registry) sinkFailed(ctx context.Context, p pipeline, name string, err error) {
+	for _, m := range p.metrics {
+		m.SinkError(name)
+	}
+	r.fallback(ctx, "Could not process log sink '%s': %v", name, err)
+}
+
+// SetSinkConcurrency lets up to `n` sinks of this Registry handle an entry at
+// the same time. One, the default, hands entries to each sink in turn, and
+// zero or less hands them to every sink at once.
+func (r *Registry) SetSinkConcurrency(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n <= 0 {
+		n = 0
+	}
+	r.concurrency = n
+}
+
+// logTo hands the entry to a single sink, unless it's a LeveledSink that
+// doesn't want it. A sink that panics is reported to the console and
+// treated as having failed, rather than taking down the caller with it.
+func (r *Registry) logTo(ctx context.Context, name string, sink Sink, entry LogEntry) (err error) {
+	if ls, ok := sink.(LeveledSink); ok && entry.Level < ls.Level() {
+		return nil
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("sink panicked: %v", p)
+		}
+	}()
+
+	if sc, ok := sink.(SinkWithContext); ok {
+		return sc.LogContext(ctx, entry)
+	}
+	return sink.Log(entry)
+}
+
+// fallback logs an error about ctxlog itself straight to the console.
+func (r *Registry) fallback(ctx context.Context, msg string, args ...interface{}) {
+	r.console.Log(r.newEntry(ctx, LevelError, msg, args...))
+}
+
+// Infof prints an informational string to the console.
+func (r *Registry) Infof(ctx context.Context, msg string, args ...interface{}) {
+	r.logf(ctx, LevelInfo, msg, args...)
+}
+
+// Debugf prints debug info if that has been enabled in the Registry.
+func (r *Registry) Debugf(ctx context.Context, msg string, args ...interface{}) {
+	if !r.enabled(LevelDebug) {
+		return
+	}
+
+	r.logf(ctx, LevelDebug, msg, args...)
+}
+
+// Warnf prints a warning to the console.
+func (r *Registry) Warnf(ctx context.Context, msg string, args ...interface{}) {
+	r.logf(ctx, LevelWarn, msg, args...)
+}
+
+// Errorf prints an error log to the console.
+func (r *Registry) Errorf(ctx context.Context, msg string, args ...interface{}) {
+	msg, args = withErrorList(ctx, msg, args)
+	r.logf(ctx, LevelError, msg, args...)
+}
+
+// Fatalf prints an error and immediately stops execution.
+func (r *Registry) Fatalf(ctx context.Context, msg string, args ...interface{}) {
+	r.logf(ctx, LevelFatal, msg, args...)
+	os.Exit(1)
+}
+
+// AppendToTrace is a helper function to append information to a traced
+// context. It's mostly used for logging request information for
+// browser clients.
+func (r *Registry) AppendToTrace(ctx context.Context, k string, v interface{}) {
+	switch ctx.(type) {
+	case LoggingContext:
+		c := ctx.(LoggingContext)
+		if c.tags == nil {
+			// Only a LoggingContext that was made by hand can be missing its
+			// set, and there's no way to give it one from here.
+			r.fallback(ctx, "Could not append tag '%s': context has no tag set", k)
+			return
+		}
+		c.tags.set(k, v)
+	default:
+		// Upgrade contexts here? There's very little circumstances where this
+		// would happen.
+	}
+}
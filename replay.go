@@ -0,0 +1,65 @@
+package ctxlog
+
+import (
+	"strings"
+)
+
+// replaySink sends a fixed set of entries to another sink.
+type replaySink struct {
+	entries []LogEntry
+	inner   Sink
+}
+
+// NewReplaySink creates a sink which, every time it's asked to log, ignores
+// the new entry and sends `entries` to `inner` instead. Useful for checking
+// how a sink handles a known set of entries, like ones captured in
+// production.
+func NewReplaySink(entries []LogEntry, inner Sink) Sink {
+	return &replaySink{entries: entries, inner: inner}
+}
+
+// Log replays the captured entries to the inner sink.
+func (rs *replaySink) Log(LogEntry) error {
+	return Replay(rs.entries, rs.inner)
+}
+
+// Replay sends each of `entries` to `sink`, and returns all of the errors
+// it ran into along the way.
+func Replay(entries []LogEntry, sink Sink) error {
+	var errs multiError
+	for _, e := range entries {
+		if err := sink.Log(e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs.errOrNil()
+}
+
+// multiError collects several errors into one.
+type multiError []error
+
+func (m multiError) Error() string {
+	msgs := make([]string, len(m))
+	for i, err := range m {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors, so that errors.Is and errors.As look at each of
+// them.
+func (m multiError) Unwrap() []error {
+	return m
+}
+
+// errOrNil returns nil if there weren't any errors, so that callers don't end
+// up with a non-nil error interface holding an empty list.
+func (m multiError) errOrNil() error {
+	if len(m) == 0 {
+		return nil
+	}
+
+	return m
+}
@@ -0,0 +1,260 @@
+package ctxlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resource describes what's producing logs, using OpenTelemetry's resource
+// attribute names like `k8s.pod.name` or `cloud.provider`.
+type Resource map[string]string
+
+// metadataTimeout bounds each request to a cloud metadata service, which
+// won't answer at all outside of that cloud.
+const metadataTimeout = 300 * time.Millisecond
+
+// metadataClient talks to the cloud metadata services. They're link-local,
+// so it never goes through a proxy, even if HTTP_PROXY is set.
+var metadataClient = &http.Client{
+	Timeout:   metadataTimeout,
+	Transport: &http.Transport{Proxy: nil},
+}
+
+var (
+	resourceOnce sync.Once
+	resource     Resource
+)
+
+// DetectResource works out what's running this process: the container ID
+// from /proc/self/cgroup, the Kubernetes pod, namespace and node from the
+// environment variables that the downward API is usually set up to fill in,
+// and the instance from the AWS EC2, GCP or Azure metadata services. It's
+// only done once, since asking the metadata services can take a moment;
+// later calls return a copy of the same result.
+//
+// The first call blocks until every detector is done. The metadata services
+// are asked at the same time, and each request gives up after 300ms, so on
+// a host outside of those clouds it takes about that long. EC2 takes two
+// requests in a row, so a slow metadata service can make it up to 600ms.
+func DetectResource() Resource {
+	resourceOnce.Do(func() {
+		detectors := []func() Resource{
+			detectContainer,
+			detectKubernetes,
+			detectEC2,
+			detectGCP,
+			detectAzure,
+		}
+
+		var (
+			mu sync.Mutex
+			wg sync.WaitGroup
+		)
+		resource = Resource{}
+		for _, detect := range detectors {
+			wg.Add(1)
+			go func(detect func() Resource) {
+				defer wg.Done()
+
+				found := detect()
+				mu.Lock()
+				defer mu.Unlock()
+				for k, v := range found {
+					if v != "" {
+						resource[k] = v
+					}
+				}
+			}(detect)
+		}
+		wg.Wait()
+	})
+
+	ret := make(Resource, len(resource))
+	for k, v := range resource {
+		ret[k] = v
+	}
+
+	return ret
+}
+
+// UseResourceDetection adds the attributes found by DetectResource as
+// global tags. It blocks while the resource is detected, which can take up
+// to 600ms the first time; call it during startup, not on a request path.
+func UseResourceDetection() {
+	std.UseResourceDetection()
+}
+
+// UseResourceDetection adds the attributes found by DetectResource as global
+// tags on entries logged through this Registry.
+func (r *Registry) UseResourceDetection() {
+	res := DetectResource()
+
+	keys := make([]string, 0, len(res))
+	for k := range res {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, k := range keys {
+		r.setGlobalTag(k, res[k])
+	}
+}
+
+func detectContainer() Resource {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return nil
+	}
+
+	if id := parseContainerID(string(data)); id != "" {
+		return Resource{"container.id": id}
+	}
+
+	return nil
+}
+
+func detectKubernetes() Resource {
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return nil
+	}
+
+	res := Resource{}
+	vars := map[string][]string{
+		"k8s.pod.name":       {"K8S_POD_NAME", "POD_NAME"},
+		"k8s.namespace.name": {"K8S_NAMESPACE_NAME", "POD_NAMESPACE"},
+		"k8s.node.name":      {"K8S_NODE_NAME", "NODE_NAME"},
+	}
+	for attr, names := range vars {
+		for _, name := range names {
+			if v := os.Getenv(name); v != "" {
+				res[attr] = v
+				break
+			}
+		}
+	}
+
+	// Without the downward API, the pod's hostname is its name.
+	if _, ok := res["k8s.pod.name"]; !ok {
+		if h, err := os.Hostname(); err == nil {
+			res["k8s.pod.name"] = h
+		}
+	}
+
+	return res
+}
+
+func detectEC2() Resource {
+	// IMDSv2 needs a session token first.
+	token, err := metadata(http.MethodPut, "http://169.254.169.254/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "60",
+	})
+	if err != nil {
+		return nil
+	}
+
+	doc, err := metadata(http.MethodGet, "http://169.254.169.254/latest/dynamic/instance-identity/document", map[string]string{
+		"X-aws-ec2-metadata-token": token,
+	})
+	if err != nil {
+		return nil
+	}
+
+	var identity struct {
+		InstanceID       string `json:"instanceId"`
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		AccountID        string `json:"accountId"`
+	}
+	if err := json.Unmarshal([]byte(doc), &identity); err != nil {
+		return nil
+	}
+
+	return Resource{
+		"cloud.provider":          "aws",
+		"cloud.platform":          "aws_ec2",
+		"cloud.region":            identity.Region,
+		"cloud.availability_zone": identity.AvailabilityZone,
+		"cloud.account.id":        identity.AccountID,
+		"host.id":                 identity.InstanceID,
+	}
+}
+
+func detectGCP() Resource {
+	headers := map[string]string{"Metadata-Flavor": "Google"}
+	id, err := metadata(http.MethodGet, "http://metadata.google.internal/computeMetadata/v1/instance/id", headers)
+	if err != nil {
+		return nil
+	}
+
+	res := Resource{
+		"cloud.provider": "gcp",
+		"cloud.platform": "gcp_compute_engine",
+		"host.id":        id,
+	}
+
+	// Zones come back like `projects/123/zones/us-central1-a`.
+	if zone, err := metadata(http.MethodGet, "http://metadata.google.internal/computeMetadata/v1/instance/zone", headers); err == nil {
+		res["cloud.availability_zone"] = zone[strings.LastIndex(zone, "/")+1:]
+	}
+
+	return res
+}
+
+func detectAzure() Resource {
+	doc, err := metadata(http.MethodGet, "http://169.254.169.254/metadata/instance/compute?api-version=2021-02-01", map[string]string{
+		"Metadata": "true",
+	})
+	if err != nil {
+		return nil
+	}
+
+	var compute struct {
+		VMID     string `json:"vmId"`
+		Location string `json:"location"`
+	}
+	if err := json.Unmarshal([]byte(doc), &compute); err != nil || compute.VMID == "" {
+		return nil
+	}
+
+	return Resource{
+		"cloud.provider": "azure",
+		"cloud.platform": "azure_vm",
+		"cloud.region":   compute.Location,
+		"host.id":        compute.VMID,
+	}
+}
+
+// metadata makes a request to a cloud metadata service, and returns the
+// body of a successful response.
+func metadata(method, url string, headers map[string]string) (string, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := metadataClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response from %s: %s", url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return strings.TrimSpace(string(body)), err
+}
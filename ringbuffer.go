@@ -0,0 +1,86 @@
+package ctxlog
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RingBufferSink keeps the last few entries in memory, so they can be shown
+// on a diagnostics page. It is safe for concurrent use.
+type RingBufferSink struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	next    int
+	full    bool
+}
+
+// NewRingBufferSink creates a sink which holds on to the last `capacity`
+// entries it was given.
+func NewRingBufferSink(capacity int) *RingBufferSink {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &RingBufferSink{entries: make([]LogEntry, capacity)}
+}
+
+// Log stores the entry, dropping the oldest one if the buffer is full.
+func (rb *RingBufferSink) Log(entry LogEntry) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	rb.entries[rb.next] = entry
+	rb.next = (rb.next + 1) % len(rb.entries)
+	if rb.next == 0 {
+		rb.full = true
+	}
+
+	return nil
+}
+
+// Entries returns the stored entries, oldest first.
+func (rb *RingBufferSink) Entries() []LogEntry {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if !rb.full {
+		return append([]LogEntry(nil), rb.entries[:rb.next]...)
+	}
+
+	ret := make([]LogEntry, 0, len(rb.entries))
+	ret = append(ret, rb.entries[rb.next:]...)
+	return append(ret, rb.entries[:rb.next]...)
+}
+
+// EntriesAtLevel returns the stored entries with the given level name (e.g.
+// "ERROR"), oldest first.
+func (rb *RingBufferSink) EntriesAtLevel(level string) []LogEntry {
+	var ret []LogEntry
+	for _, e := range rb.Entries() {
+		if strings.EqualFold(e.Level.String(), level) {
+			ret = append(ret, e)
+		}
+	}
+
+	return ret
+}
+
+// ServeHTTP renders the stored entries as JSON. The `level` query parameter
+// limits the output to a single level.
+func (rb *RingBufferSink) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	entries := rb.Entries()
+	if level := req.URL.Query().Get("level"); level != "" {
+		entries = rb.EntriesAtLevel(level)
+	}
+
+	if entries == nil {
+		entries = []LogEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
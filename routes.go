@@ -0,0 +1,76 @@
+package ctxlog
+
+import (
+	"context"
+)
+
+// route sends entries that match a condition to a sink.
+type route struct {
+	condition func(entry LogEntry) bool
+	sink      string
+}
+
+// RegisterRoute sends entries for which `condition` returns true to the sink
+// named `sinkName`, as well as to all of the usual sinks. Sinks that should
+// only get routed entries are added with UseRouteSink. The name can also be
+// that of a sink added with UseSink, which is then sent matching entries a
+// second time. Routes are checked after the usual sinks have had the entry.
+func RegisterRoute(condition func(entry LogEntry) bool, sinkName string) {
+	std.RegisterRoute(condition, sinkName)
+}
+
+// UseRouteSink adds a sink which only receives entries sent to it by
+// RegisterRoute.
+func UseRouteSink(name string, s Sink) {
+	std.UseRouteSink(name, s)
+}
+
+// RegisterRoute sends entries logged through this Registry for which
+// `condition` returns true to the sink named `sinkName`.
+func (r *Registry) RegisterRoute(condition func(entry LogEntry) bool, sinkName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.routes = append(r.routes, route{condition: condition, sink: sinkName})
+}
+
+// UseRouteSink adds a sink to this Registry which only receives entries sent
+// to it by RegisterRoute.
+func (r *Registry) UseRouteSink(name string, s Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.routeSinks = withSink(r.routeSinks, name, s)
+	attach(s, r)
+}
+
+// route hands the entry to the sinks whose conditions it matches, once each,
+// and returns the errors they had. Route sinks are looked for first, and then
+// the usual sinks.
+func (r *Registry) route(ctx context.Context, p pipeline, entry LogEntry) []error {
+	var (
+		errs []error
+		sent map[string]bool
+	)
+	for _, rt := range p.routes {
+		sink, ok := p.routeSinks[rt.sink]
+		if !ok {
+			sink, ok = p.sinks[rt.sink]
+		}
+		if !ok || sent[rt.sink] || !rt.condition(entry) {
+			continue
+		}
+
+		if sent == nil {
+			sent = map[string]bool{}
+		}
+		sent[rt.sink] = true
+
+		if err := r.logTo(ctx, rt.sink, sink, entry); err != nil {
+			errs = append(errs, err)
+			r.sinkFailed(ctx, p, rt.sink, err)
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,58 @@
+package ctxlog
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRegisterRoute(t *testing.T) {
+	enterprise := func(e LogEntry) bool {
+		v, _ := e.tag("tenant_id")
+		return v == "enterprise-1"
+	}
+
+	tests := []struct {
+		name      string
+		setup     func(r *Registry, target Sink)
+		tenant    string
+		wantUsual int
+		want      int
+	}{
+		{"match", func(r *Registry, s Sink) {
+			r.UseRouteSink("target", s)
+			r.RegisterRoute(enterprise, "target")
+		}, "enterprise-1", 1, 1},
+		{"no match", func(r *Registry, s Sink) {
+			r.UseRouteSink("target", s)
+			r.RegisterRoute(enterprise, "target")
+		}, "free", 1, 0},
+		{"two routes to one sink", func(r *Registry, s Sink) {
+			r.UseRouteSink("target", s)
+			r.RegisterRoute(enterprise, "target")
+			r.RegisterRoute(func(LogEntry) bool { return true }, "target")
+		}, "enterprise-1", 1, 1},
+		{"usual sink", func(r *Registry, s Sink) {
+			r.UseSink("target", s)
+			r.RegisterRoute(enterprise, "target")
+		}, "enterprise-1", 1, 2},
+		{"missing sink", func(r *Registry, s Sink) {
+			r.RegisterRoute(enterprise, "target")
+		}, "enterprise-1", 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, usual := newRecordingRegistry()
+			target := &recordingSink{}
+			tt.setup(r, target)
+
+			r.Infof(r.With(context.Background(), "tenant_id", tt.tenant), "hello")
+
+			if got := len(usual.Entries()); got != tt.wantUsual {
+				t.Errorf("usual sink has %d entries, want %d", got, tt.wantUsual)
+			}
+			if got := len(target.Entries()); got != tt.want {
+				t.Errorf("routed sink has %d entries, want %d", got, tt.want)
+			}
+		})
+	}
+}
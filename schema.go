@@ -0,0 +1,80 @@
+package ctxlog
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	schemaOnce sync.Once
+	schema     []byte
+)
+
+// entryFields describes how each field of LogEntry appears in its JSON form,
+// as produced by ToJSON.
+var entryFields = map[string]struct {
+	key         string
+	description string
+}{
+	"Time":       {"time", "When the entry was logged, in RFC 3339 format with nanoseconds."},
+	"Level":      {"level", "The severity of the entry."},
+	"Message":    {"msg", "The formatted log message."},
+	"Tags":       {"tags", "The tags carried by the logging context. Tags with more than one value are arrays."},
+	"InstanceID": {"instance_id", "The UUID of the process that logged the entry."},
+}
+
+// LogEntryJSONSchema returns a JSON Schema (draft 7) document describing log
+// entries as they're encoded to JSON, for consumers written in other
+// languages.
+func LogEntryJSONSchema() []byte {
+	schemaOnce.Do(func() {
+		properties := map[string]interface{}{}
+		var required []string
+
+		t := reflect.TypeOf(LogEntry{})
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			desc, ok := entryFields[f.Name]
+			if !ok {
+				// A new field that ToJSON doesn't know about yet.
+				continue
+			}
+
+			prop := schemaType(f.Type)
+			prop["description"] = desc.description
+			properties[desc.key] = prop
+			required = append(required, desc.key)
+		}
+
+		schema, _ = json.MarshalIndent(map[string]interface{}{
+			"$schema":              "http://json-schema.org/draft-07/schema#",
+			"title":                "LogEntry",
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}, "", "  ")
+	})
+
+	return append([]byte(nil), schema...)
+}
+
+// schemaType returns the JSON Schema for a LogEntry field of type `t`.
+func schemaType(t reflect.Type) map[string]interface{} {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(Level(0)):
+		var names []string
+		for l := LevelDebug; l <= LevelFatal; l++ {
+			names = append(names, l.String())
+		}
+		return map[string]interface{}{"type": "string", "enum": names}
+	case reflect.TypeOf([]Tag(nil)):
+		return map[string]interface{}{"type": "object"}
+	}
+
+	return map[string]interface{}{"type": "string"}
+}
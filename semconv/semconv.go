@@ -0,0 +1,54 @@
+// Package semconv adds tags named after the OpenTelemetry semantic
+// conventions to ctxlog contexts, so that logs can be queried the same way as
+// traces and metrics from OpenTelemetry-based tools. The tags keep their
+// names even inside a ctxlog namespace.
+package semconv
+
+import (
+	"context"
+
+	"github.com/silversupreme/ctxlog"
+)
+
+// The OpenTelemetry attribute names that the helpers in this package add.
+const (
+	HTTPRequestMethodKey = "http.request.method"
+	URLFullKey           = "url.full"
+
+	DBSystemKey    = "db.system"
+	DBStatementKey = "db.statement"
+
+	RPCSystemKey  = "rpc.system"
+	RPCServiceKey = "rpc.service"
+	RPCMethodKey  = "rpc.method"
+)
+
+// HTTPClientRequest adds the `http.request.method` and `url.full` tags for an
+// outgoing HTTP request, like "GET" and "https://example.com/users/1".
+func HTTPClientRequest(ctx context.Context, method, url string) context.Context {
+	return ctxlog.WithAllUnnamespaced(ctx,
+		ctxlog.Tag{K: HTTPRequestMethodKey, V: method, Override: true},
+		ctxlog.Tag{K: URLFullKey, V: url, Override: true},
+	)
+}
+
+// DBStatement adds the `db.system` and `db.statement` tags for a database
+// query, like "postgresql" and "SELECT * FROM users WHERE id = $1". The
+// statement is logged as it's given, so it shouldn't have sensitive values
+// inlined in it; see ctxlog.WithSQL for redacting arguments.
+func DBStatement(ctx context.Context, system, statement string) context.Context {
+	return ctxlog.WithAllUnnamespaced(ctx,
+		ctxlog.Tag{K: DBSystemKey, V: system, Override: true},
+		ctxlog.Tag{K: DBStatementKey, V: statement, Override: true},
+	)
+}
+
+// RPCCall adds the `rpc.system`, `rpc.service` and `rpc.method` tags for a
+// remote procedure call, like "grpc", "myservice.EchoService" and "Echo".
+func RPCCall(ctx context.Context, system, service, method string) context.Context {
+	return ctxlog.WithAllUnnamespaced(ctx,
+		ctxlog.Tag{K: RPCSystemKey, V: system, Override: true},
+		ctxlog.Tag{K: RPCServiceKey, V: service, Override: true},
+		ctxlog.Tag{K: RPCMethodKey, V: method, Override: true},
+	)
+}
@@ -0,0 +1,121 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// drainer is implemented by sinks which hold on to entries, like buffered or
+// batching sinks, and need to be given a chance to send them before the
+// program exits.
+type drainer interface {
+	Close() error
+}
+
+// flusher is implemented by sinks which can send what they're holding on to
+// without being closed.
+type flusher interface {
+	Flush() error
+}
+
+// drainFunc returns what drains `s`: closing it if it can be closed, or else
+// flushing it. It returns nil if `s` doesn't hold on to entries.
+func drainFunc(s Sink) func() error {
+	switch s := s.(type) {
+	case drainer:
+		return s.Close
+	case flusher:
+		return s.Flush
+	}
+
+	return nil
+}
+
+// closeSink drains `s`, for sinks that wrap it and are being closed.
+func closeSink(s Sink) error {
+	if drain := drainFunc(s); drain != nil {
+		return drain()
+	}
+
+	return nil
+}
+
+// flushSink flushes `s` if it can be flushed, for sinks that wrap it.
+func flushSink(s Sink) error {
+	if f, ok := s.(flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+// Shutdown drains every sink that holds on to entries, including route sinks,
+// closing those that can be closed and flushing the rest, like
+// http.Server.Shutdown. If they all
+// finish before `ctx` is done, it returns any errors they reported; if not,
+// it returns an error wrapping ctx.Err() (usually context.DeadlineExceeded)
+// which names the sinks that didn't.
+func Shutdown(ctx context.Context) error {
+	return std.Shutdown(ctx)
+}
+
+// Shutdown drains every sink in this Registry that holds on to entries.
+func (r *Registry) Shutdown(ctx context.Context) error {
+	r.mu.RLock()
+	// Keyed by how the sinks are described in errors, since a sink and a
+	// route sink can have the same name.
+	drains := map[string]func() error{}
+	for name, sink := range r.sinks {
+		if drain := drainFunc(sink); drain != nil {
+			drains[fmt.Sprintf("sink '%s'", name)] = drain
+		}
+	}
+	for name, sink := range r.routeSinks {
+		if drain := drainFunc(sink); drain != nil {
+			drains[fmt.Sprintf("route sink '%s'", name)] = drain
+		}
+	}
+	r.mu.RUnlock()
+
+	type result struct {
+		name string
+		err  error
+	}
+
+	// Buffered, so that sinks which finish after the deadline don't leak
+	// their goroutine.
+	results := make(chan result, len(drains))
+	for name, drain := range drains {
+		go func(name string, drain func() error) {
+			results <- result{name, drain()}
+		}(name, drain)
+	}
+
+	var errs multiError
+	pending := make(map[string]bool, len(drains))
+	for name := range drains {
+		pending[name] = true
+	}
+
+	for len(pending) > 0 {
+		select {
+		case res := <-results:
+			delete(pending, res.name)
+			if res.err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", res.name, res.err))
+			}
+		case <-ctx.Done():
+			names := make([]string, 0, len(pending))
+			for name := range pending {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			return fmt.Errorf("%w: did not drain: %s", ctx.Err(), strings.Join(names, ", "))
+		}
+	}
+
+	return errs.errOrNil()
+}
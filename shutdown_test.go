@@ -0,0 +1,132 @@
+package ctxlog
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// drainSink counts how many times it's closed or flushed, and returns `err`
+// from them after waiting `delay`.
+type drainSink struct {
+	NoopSink
+	delay   time.Duration
+	err     error
+	drained int32
+}
+
+func (ds *drainSink) drain() error {
+	time.Sleep(ds.delay)
+	atomic.AddInt32(&ds.drained, 1)
+	return ds.err
+}
+
+// closingSink can be closed.
+type closingSink struct{ drainSink }
+
+func (cs *closingSink) Close() error { return cs.drain() }
+
+// flushingSink can be flushed.
+type flushingSink struct{ drainSink }
+
+func (fs *flushingSink) Flush() error { return fs.drain() }
+
+func TestShutdown(t *testing.T) {
+	failure := errors.New("drain failed")
+
+	tests := []struct {
+		name    string
+		add     func(r *Registry, inner Sink)
+		inner   func() (Sink, *drainSink)
+		timeout time.Duration
+		wantErr string
+	}{
+		{
+			name:  "closer",
+			add:   func(r *Registry, s Sink) { r.UseSink("s", s) },
+			inner: func() (Sink, *drainSink) { s := &closingSink{}; return s, &s.drainSink },
+		},
+		{
+			name:  "flusher",
+			add:   func(r *Registry, s Sink) { r.UseSink("s", s) },
+			inner: func() (Sink, *drainSink) { s := &flushingSink{}; return s, &s.drainSink },
+		},
+		{
+			name:  "route sink",
+			add:   func(r *Registry, s Sink) { r.UseRouteSink("s", s) },
+			inner: func() (Sink, *drainSink) { s := &closingSink{}; return s, &s.drainSink },
+		},
+		{
+			name:  "timeout wrapper",
+			add:   func(r *Registry, s Sink) { r.UseSink("s", NewTimeoutSink(s, time.Second)) },
+			inner: func() (Sink, *drainSink) { s := &flushingSink{}; return s, &s.drainSink },
+		},
+		{
+			name:  "circuit breaker wrapper",
+			add:   func(r *Registry, s Sink) { r.UseSink("s", NewCircuitBreakerSink(s, 1, time.Second)) },
+			inner: func() (Sink, *drainSink) { s := &closingSink{}; return s, &s.drainSink },
+		},
+		{
+			name:  "instrumented wrapper",
+			add:   func(r *Registry, s Sink) { r.UseSink("s", NewInstrumentedSink("s", s)) },
+			inner: func() (Sink, *drainSink) { s := &flushingSink{}; return s, &s.drainSink },
+		},
+		{
+			name: "error",
+			add:  func(r *Registry, s Sink) { r.UseSink("s", s) },
+			inner: func() (Sink, *drainSink) {
+				s := &closingSink{drainSink{err: failure}}
+				return s, &s.drainSink
+			},
+			wantErr: "sink 's': drain failed",
+		},
+		{
+			name: "deadline",
+			add:  func(r *Registry, s Sink) { r.UseRouteSink("s", s) },
+			inner: func() (Sink, *drainSink) {
+				s := &closingSink{drainSink{delay: 100 * time.Millisecond}}
+				return s, &s.drainSink
+			},
+			timeout: 10 * time.Millisecond,
+			wantErr: "context deadline exceeded: did not drain: route sink 's'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			s, inner := tt.inner()
+			tt.add(r, s)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			err := r.Shutdown(ctx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Shutdown returned %v", err)
+				}
+				if got := atomic.LoadInt32(&inner.drained); got != 1 {
+					t.Errorf("sink drained %d times, want 1", got)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Shutdown returned %v, want %q", err, tt.wantErr)
+			}
+			if tt.timeout > 0 && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Shutdown returned %v, want it to wrap the context's error", err)
+			}
+			if tt.timeout == 0 && !errors.Is(err, failure) {
+				t.Errorf("Shutdown returned %v, want it to wrap the sink's error", err)
+			}
+		})
+	}
+}
@@ -1,63 +1,335 @@
 package ctxlog
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/fatih/color"
 )
 
 // Sink implementers accept event data and store it for later analysis.
+// Each sink decides for itself which entries it records.
 type Sink interface {
-	Log(ctx context.Context, c *color.Color, levelname string, msg string, args ...interface{}) error
+	Log(entry LogEntry) error
+}
+
+// SinkWithContext is a Sink that wants the context that an entry was logged
+// with, to respect its deadline or cancellation while handling the entry.
+// LogContext is called in place of Log.
+type SinkWithContext interface {
+	Sink
+	LogContext(ctx context.Context, entry LogEntry) error
 }
 
-var (
-	// Keep the ConsoleSink around as a backup in case other sinks fail.
-	console = &ConsoleSink{}
+// LeveledSink is a Sink that only wants entries at or above a certain
+// level. Entries below it aren't handed to the sink at all, which saves
+// encoding and sending entries that its backend would throw away.
+type LeveledSink interface {
+	Sink
+	SetLevel(level Level)
+	Level() Level
+}
 
-	sinks = map[string]Sink{
-		"console": console,
+// attachedSink is implemented by sinks that run into problems in the
+// background, like failed flushes, so that they can report them through the
+// Registry they were added to rather than the default one.
+type attachedSink interface {
+	attach(r *Registry)
+}
+
+// sinkReporter reports a sink's background problems to the console of the
+// Registry it was last added to, or the default one if it hasn't been.
+type sinkReporter struct {
+	r atomic.Pointer[Registry]
+}
+
+func (sr *sinkReporter) attach(r *Registry) {
+	sr.r.Store(r)
+}
+
+// report logs an error about the sink to the console.
+func (sr *sinkReporter) report(msg string, args ...interface{}) {
+	r := sr.r.Load()
+	if r == nil {
+		r = std
 	}
-)
+
+	r.fallback(context.Background(), msg, args...)
+}
+
+// attach tells `s`, if it wants to know, that it's been added to `r`.
+func attach(s Sink, r *Registry) {
+	if as, ok := s.(attachedSink); ok {
+		as.attach(r)
+	}
+}
 
 // UseSink adds a sink which will receive all logs output by the application.
 func UseSink(name string, s Sink) {
-	sinks[name] = s
+	std.UseSink(name, s)
+}
+
+// LookupSink returns the sink registered under `name`, if there is one.
+func LookupSink(name string) (Sink, bool) {
+	return std.LookupSink(name)
+}
+
+// NoopSink throws away every entry it's given.
+type NoopSink struct{}
+
+// NewNoopSink creates a sink that does nothing. Use it in place of the
+// console to silence logging:
+//
+//	ctxlog.UseSink("console", ctxlog.NewNoopSink())
+func NewNoopSink() Sink {
+	return NoopSink{}
+}
+
+// Log does nothing.
+func (NoopSink) Log(LogEntry) error {
+	return nil
+}
+
+// SetSinkConcurrency lets up to `n` sinks handle an entry at the same time,
+// so that slow sinks don't hold each other up. One, the default, hands
+// entries to each sink in turn, and zero or less hands them to every sink at
+// once. Log calls still wait for every sink either way.
+func SetSinkConcurrency(n int) {
+	std.SetSinkConcurrency(n)
+}
+
+// RemoveSink stops the sink registered under `name` from receiving logs.
+func RemoveSink(name string) {
+	std.RemoveSink(name)
+}
+
+// bufPool holds the buffers that ConsoleSink formats lines into. A line's
+// buffer is done with as soon as it has been written, unlike contexts and
+// entries which can be held onto by anything, so it's safe to reuse.
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
 }
 
 // ConsoleSink dumps out events to the console with colorized tags.
-type ConsoleSink struct{}
+type ConsoleSink struct {
+	sinkCounters
+
+	minLevel int32
+
+	// Where lines are written; os.Stdout if nil.
+	out io.Writer
+
+	// Whether duration tags are printed like "1.04s" rather than as numbers.
+	humanDurations bool
+
+	// Whether the message and tag values are colored too, not just the level
+	// and tag keys.
+	colorizedMessage bool
+
+	// Whether messages are indented by how deeply nested their span is.
+	indentByDepth bool
+}
+
+// ConsoleOption configures a ConsoleSink created with NewConsoleSink.
+type ConsoleOption func(*ConsoleSink)
+
+// WithHumanDurations prints tags whose keys end in `_ms`, `_us` or `_ns` as
+// durations like "1.04s" or "500µs", rather than as plain numbers.
+func WithHumanDurations(enabled bool) ConsoleOption {
+	return func(cs *ConsoleSink) {
+		cs.humanDurations = enabled
+	}
+}
+
+// WithColorizedMessage prints the message in the color of the entry's
+// level, and tag values in a lighter weight of it, rather than only coloring
+// the level and tag keys. Lines are easier to pick out when scanning
+// through logs.
+func WithColorizedMessage(enabled bool) ConsoleOption {
+	return func(cs *ConsoleSink) {
+		cs.colorizedMessage = enabled
+	}
+}
+
+// WithIndentByDepth indents messages by two spaces for each span they're
+// nested in, going by the `span_depth` tag, so that nested spans look like a
+// tree.
+func WithIndentByDepth(enabled bool) ConsoleOption {
+	return func(cs *ConsoleSink) {
+		cs.indentByDepth = enabled
+	}
+}
+
+// NewConsoleSink creates a ConsoleSink, which can replace the default one
+// with UseSink("console", ...).
+func NewConsoleSink(opts ...ConsoleOption) *ConsoleSink {
+	cs := &ConsoleSink{}
+	for _, opt := range opts {
+		opt(cs)
+	}
+
+	return cs
+}
+
+// SetMinLevel stops the console from printing entries below `l`.
+func (cs *ConsoleSink) SetMinLevel(l Level) {
+	atomic.StoreInt32(&cs.minLevel, int32(l))
+}
+
+// SetLevel is the same as SetMinLevel, so that ConsoleSink is a LeveledSink.
+func (cs *ConsoleSink) SetLevel(l Level) {
+	cs.SetMinLevel(l)
+}
+
+// Level returns the level below which the console doesn't print entries.
+func (cs *ConsoleSink) Level() Level {
+	return Level(atomic.LoadInt32(&cs.minLevel))
+}
 
 // Log prints to the console with colorized tags.
-func (cs *ConsoleSink) Log(ctx context.Context, c *color.Color, levelname string, msg string, args ...interface{}) error {
+func (cs *ConsoleSink) Log(entry LogEntry) error {
+	if entry.Level < cs.Level() {
+		return nil
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer bufPool.Put(buf)
+
 	// TODO(silversupreme): Implement some logging to like JSON here when not attached to a TTY.
-	msg = fmt.Sprintf(msg, args...)
-	s := fmt.Sprintf("[%s] (%-30s) %-40s", c.Sprintf("%-6s", levelname), time.Now().Format(time.RFC3339Nano), msg)
-
-	switch ctx.(type) {
-	case LoggingContext:
-		lc := ctx.(LoggingContext)
-		// Ensure that tags are printed in the order that they were added,
-		// which creates a nice nesting effect for logs.
-		for _, k := range lc.order {
-			val := lc.tags[k]
-
-			// Special-case for single-item lists, to just print that single
-			// item. Helps preserve the normal expected formatting.
-			if len(val) == 1 {
-				s = fmt.Sprintf("%s %s=%v", s, c.Sprint(k), lc.tags[k][0])
-			} else {
-				s = fmt.Sprintf("%s %s=%v", s, c.Sprint(k), lc.tags[k])
+	c := entry.Level.color()
+	msg := entry.Message
+	if cs.indentByDepth {
+		if depth, ok := entry.tag("span_depth"); ok {
+			if d, ok := depthValue(depth); ok && d > 0 {
+				msg = strings.Repeat("  ", d) + msg
 			}
 		}
-	default:
+	}
+	msg = fmt.Sprintf("%-40s", msg)
+	if cs.colorizedMessage {
+		msg = c.Sprint(msg)
+	}
+	fmt.Fprintf(buf, "[%s] (%-30s) %s", c.Sprintf("%-6s", entry.Level), entry.Time.Format(time.RFC3339Nano), msg)
+
+	// Ensure that tags are printed in the order that they were added,
+	// which creates a nice nesting effect for logs. Tags in the same
+	// namespace are printed together, where the first of them was added.
+	var printed map[string]bool
+	for _, t := range entry.Tags {
+		ns := namespaceOf(t.K)
+		if ns == "" {
+			fmt.Fprintf(buf, " %s=%s", c.Sprint(t.K), cs.value(entry.Level, t))
+			continue
+		}
+
+		if printed[ns] {
+			continue
+		}
+		if printed == nil {
+			printed = map[string]bool{}
+		}
+		printed[ns] = true
+
+		var group []string
+		for _, g := range entry.Tags {
+			if namespaceOf(g.K) == ns {
+				group = append(group, fmt.Sprintf("%s=%s", c.Sprint(g.K[len(ns)+1:]), cs.value(entry.Level, g)))
+			}
+		}
+
+		if len(group) == 1 {
+			fmt.Fprintf(buf, " %s=%s", c.Sprint(t.K), cs.value(entry.Level, t))
+		} else {
+			fmt.Fprintf(buf, " %s{%s}", c.Sprint(ns+"."), strings.Join(group, " "))
+		}
 	}
 
 	// Always include the global UUID in logs, at the end.
-	s = fmt.Sprintf("%s %s=%s", s, c.Sprint("instance_id"), globalUUID.String())
-	fmt.Println(s)
+	fmt.Fprintf(buf, " %s=%s\n", c.Sprint("instance_id"), entry.InstanceID)
 
-	return nil
+	out := cs.out
+	if out == nil {
+		out = os.Stdout
+	}
+	n, err := out.Write(buf.Bytes())
+	cs.record(n, err)
+
+	return err
+}
+
+// durationSuffixes are the tag key suffixes that WithHumanDurations applies
+// to, and the unit of each.
+var durationSuffixes = map[string]time.Duration{
+	"_ms": time.Millisecond,
+	"_us": time.Microsecond,
+	"_ns": time.Nanosecond,
+}
+
+// value returns the text to print for the value of a tag in an entry at
+// `level`.
+func (cs *ConsoleSink) value(level Level, t Tag) string {
+	v := fmt.Sprint(cs.duration(t))
+	if cs.colorizedMessage {
+		return level.valueColor().Sprint(v)
+	}
+
+	return v
+}
+
+// duration returns the value of a duration tag as a human-readable string,
+// if that's turned on. Other tags are returned as they are.
+func (cs *ConsoleSink) duration(t Tag) interface{} {
+	if !cs.humanDurations || len(t.K) < 3 {
+		return t.V
+	}
+
+	unit, ok := durationSuffixes[t.K[len(t.K)-3:]]
+	if !ok {
+		return t.V
+	}
+
+	var n int64
+	switch v := t.V.(type) {
+	case int:
+		n = int64(v)
+	case int64:
+		n = v
+	case int32:
+		n = int64(v)
+	default:
+		return t.V
+	}
+
+	return humanDuration(time.Duration(n) * unit)
+}
+
+// humanDuration formats `d` in the largest unit that it's at least one of,
+// to two decimal places at most: "1.04s", "50ms", "500µs".
+func humanDuration(d time.Duration) string {
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{time.Second, "s"},
+		{time.Millisecond, "ms"},
+		{time.Microsecond, "µs"},
+	}
+
+	for _, u := range units {
+		if d >= u.size || -d >= u.size {
+			s := strconv.FormatFloat(float64(d)/float64(u.size), 'f', 2, 64)
+			return strings.TrimSuffix(strings.TrimRight(s, "0"), ".") + u.name
+		}
+	}
+
+	return strconv.FormatInt(int64(d), 10) + "ns"
 }
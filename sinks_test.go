@@ -0,0 +1,144 @@
+package ctxlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// errSink fails every entry with `err`.
+type errSink struct{ err error }
+
+func (es errSink) Log(LogEntry) error { return es.err }
+
+// panicSink panics on every entry.
+type panicSink struct{}
+
+func (panicSink) Log(LogEntry) error { panic("sink exploded") }
+
+func TestDeliver(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		extra       Sink
+		wantConsole string
+	}{
+		{"one at a time", 1, nil, ""},
+		{"all at once", 0, nil, ""},
+		{"limited", 2, nil, ""},
+		{"failing sink", 1, errSink{errors.New("disk full")}, "Could not process log sink 'extra': disk full"},
+		{"failing sink concurrently", 0, errSink{errors.New("disk full")}, "Could not process log sink 'extra': disk full"},
+		{"panicking sink", 1, panicSink{}, "Could not process log sink 'extra': sink panicked: sink exploded"},
+		{"leveled sink", 1, &ctxSink{level: LevelError}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, sink := newRecordingRegistry()
+			console := &bytes.Buffer{}
+			r.console.out = console
+			r.SetSinkConcurrency(tt.concurrency)
+
+			others := []*recordingSink{sink, {}, {}}
+			r.UseSink("second", others[1])
+			r.UseSink("third", others[2])
+			if tt.extra != nil {
+				r.UseSink("extra", tt.extra)
+			}
+
+			r.Infof(context.Background(), "hello")
+
+			for i, s := range others {
+				if got := len(s.Entries()); got != 1 {
+					t.Errorf("sink %d has %d entries, want 1", i, got)
+				}
+			}
+			if tt.wantConsole == "" && console.Len() > 0 {
+				t.Errorf("console has %q, want nothing", console.String())
+			}
+			if !strings.Contains(console.String(), tt.wantConsole) {
+				t.Errorf("console has %q, want %q", console.String(), tt.wantConsole)
+			}
+			if cs, ok := tt.extra.(*ctxSink); ok && len(cs.ctxs) != 0 {
+				t.Errorf("leveled sink was given an entry below its level")
+			}
+		})
+	}
+}
+
+func TestConsoleSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ConsoleOption
+		level   Level
+		min     Level
+		tags    []Tag
+		want    []string
+		notWant []string
+	}{
+		{
+			name:  "plain",
+			level: LevelInfo,
+			tags:  []Tag{{K: "user", V: "alice"}},
+			want:  []string{"hello", "user", "=alice", "instance_id", "=instance"},
+		},
+		{
+			name:    "below the minimum level",
+			level:   LevelDebug,
+			min:     LevelInfo,
+			notWant: []string{"hello"},
+		},
+		{
+			name:  "human durations",
+			opts:  []ConsoleOption{WithHumanDurations(true)},
+			level: LevelInfo,
+			tags:  []Tag{{K: "dur_ms", V: int64(1500)}},
+			want:  []string{"=1.5s"},
+		},
+		{
+			name:  "numeric durations",
+			level: LevelInfo,
+			tags:  []Tag{{K: "dur_ms", V: int64(1500)}},
+			want:  []string{"=1500"},
+		},
+		{
+			name:  "indented",
+			opts:  []ConsoleOption{WithIndentByDepth(true)},
+			level: LevelInfo,
+			tags:  []Tag{{K: "span_depth", V: 2}},
+			want:  []string{"    hello"},
+		},
+		{
+			name:  "namespaced",
+			level: LevelInfo,
+			tags:  []Tag{{K: "http.method", V: "GET"}, {K: "http.status", V: 200}},
+			want:  []string{"http.", "method", "=GET", "status", "=200}"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			cs := NewConsoleSink(tt.opts...)
+			cs.out = out
+			cs.SetMinLevel(tt.min)
+
+			err := cs.Log(LogEntry{Time: time.Now(), Level: tt.level, Message: "hello", Tags: tt.tags, InstanceID: "instance"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, s := range tt.want {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("line %q doesn't have %q", out.String(), s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(out.String(), s) {
+					t.Errorf("line %q has %q", out.String(), s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package ctxlog
+
+import (
+	"context"
+	"reflect"
+)
+
+// Snapshot is the set of tags that a context had at some point, which can be
+// put back with RestoreSnapshot.
+type Snapshot struct {
+	vals  map[string][]interface{}
+	order []string
+}
+
+// TagSnapshot captures the tags that `ctx` has now.
+func TagSnapshot(ctx context.Context) Snapshot {
+	lc, ok := ctx.(LoggingContext)
+	if !ok {
+		return Snapshot{}
+	}
+
+	vals, order := lc.tags.clone()
+	return Snapshot{vals: vals, order: order}
+}
+
+// RestoreSnapshot returns a context like `ctx`, but with the tags in `s`
+// instead of its own.
+//
+//	snap := ctxlog.TagSnapshot(ctx)
+//	for attempt := 0; attempt < 3; attempt++ {
+//		ctx = ctxlog.RestoreSnapshot(ctx, snap)
+//		...
+//	}
+func RestoreSnapshot(ctx context.Context, s Snapshot) context.Context {
+	lc, ok := ctx.(LoggingContext)
+	if !ok {
+		lc = LoggingContext{Context: ctx}
+	}
+
+	vals := make(map[string][]interface{}, len(s.vals))
+	for k, v := range s.vals {
+		vals[k] = v
+	}
+	lc.tags = newTagSet(vals, append([]string(nil), s.order...))
+
+	return lc
+}
+
+// Tags returns the tags in the snapshot, in the order they were added.
+func (s Snapshot) Tags() []Tag {
+	ret := make([]Tag, 0, len(s.order))
+	for _, k := range s.order {
+		ret = append(ret, snapshotTag(k, s.vals[k]))
+	}
+
+	return ret
+}
+
+// TagDiff is what changed between two snapshots.
+type TagDiff struct {
+	// Tags which are only in the second snapshot.
+	Added []Tag
+	// Tags which are only in the first snapshot.
+	Removed []Tag
+	// Tags which are in both with different values, with the values from
+	// the second snapshot.
+	Changed []Tag
+}
+
+// DiffSnapshot returns the tags that were added, removed or changed going
+// from `s1` to `s2`.
+func DiffSnapshot(s1, s2 Snapshot) TagDiff {
+	var diff TagDiff
+	for _, k := range s2.order {
+		old, ok := s1.vals[k]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, snapshotTag(k, s2.vals[k]))
+		case !reflect.DeepEqual(old, s2.vals[k]):
+			diff.Changed = append(diff.Changed, snapshotTag(k, s2.vals[k]))
+		}
+	}
+
+	for _, k := range s1.order {
+		if _, ok := s2.vals[k]; !ok {
+			diff.Removed = append(diff.Removed, snapshotTag(k, s1.vals[k]))
+		}
+	}
+
+	return diff
+}
+
+// snapshotTag returns the tag `k` with the values `vals`, using just the
+// value if there's only one, like entries do.
+func snapshotTag(k string, vals []interface{}) Tag {
+	if len(vals) == 1 {
+		return Tag{K: k, V: vals[0]}
+	}
+
+	return Tag{K: k, V: vals}
+}
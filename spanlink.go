@@ -0,0 +1,75 @@
+package ctxlog
+
+import (
+	"context"
+	"sync"
+)
+
+// SpanLink points from a span to another span that it's related to, but
+// isn't a child of, like each of the messages a batch was built from.
+type SpanLink struct {
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// spanKey is the context key for the *spanState of the current span.
+type spanKey struct{}
+
+// spanState holds what's added to a span while it runs, to be logged when
+// it ends.
+type spanState struct {
+	mu    sync.Mutex
+	links []SpanLink
+}
+
+// AddSpanLink links the current span to the span `linkedSpanID` in the trace
+// `linkedTraceID`. Links are logged as the `links` tag when the span ends.
+// Outside of a span, the link is added to the context's tags instead.
+func AddSpanLink(ctx context.Context, linkedTraceID, linkedSpanID string, attrs ...Tag) context.Context {
+	return registryFor(ctx).AddSpanLink(ctx, linkedTraceID, linkedSpanID, attrs...)
+}
+
+// AddSpanLink links the current span to another span, through this Registry.
+func (r *Registry) AddSpanLink(ctx context.Context, linkedTraceID, linkedSpanID string, attrs ...Tag) context.Context {
+	link := SpanLink{TraceID: linkedTraceID, SpanID: linkedSpanID}
+	if len(attrs) > 0 {
+		link.Attributes = make(map[string]interface{}, len(attrs))
+		for _, t := range attrs {
+			link.Attributes[t.K] = t.V
+		}
+	}
+
+	if s, ok := ctx.Value(spanKey{}).(*spanState); ok {
+		s.mu.Lock()
+		s.links = append(s.links, link)
+		s.mu.Unlock()
+		return ctx
+	}
+
+	return r.withAll(ctx, Tag{K: "links", V: link})
+}
+
+// withSpanState returns `ctx` with a new spanState for a span starting in
+// it.
+func withSpanState(ctx context.Context) (context.Context, *spanState) {
+	s := &spanState{}
+	if lc, ok := ctx.(LoggingContext); ok {
+		lc.Context = context.WithValue(lc.Context, spanKey{}, s)
+		return lc, s
+	}
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// tags returns the tags that the span's state adds when it ends.
+func (s *spanState) tags() []Tag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.links) == 0 {
+		return nil
+	}
+
+	return []Tag{{K: "links", V: append([]SpanLink(nil), s.links...), Override: true}}
+}
@@ -0,0 +1,107 @@
+package ctxlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"unicode/utf8"
+)
+
+// maxSQLQuery is the length that queries are truncated to by WithSQL.
+const maxSQLQuery = 256
+
+// SQLOption configures the tags that WithSQL adds.
+type SQLOption func(*sqlConfig)
+
+type sqlConfig struct {
+	args bool
+}
+
+// WithSQLArgs also adds the query's arguments as `sql_args`. Arguments at
+// positions registered with RegisterSensitiveSQLArgs are redacted.
+func WithSQLArgs(enabled bool) SQLOption {
+	return func(c *sqlConfig) {
+		c.args = enabled
+	}
+}
+
+// RegisterSensitiveSQLArgs marks the arguments of `query` at the given
+// positions, counting from zero, as ones that must never be logged.
+func RegisterSensitiveSQLArgs(query string, positions ...int) {
+	std.RegisterSensitiveSQLArgs(query, positions...)
+}
+
+// RegisterSensitiveSQLArgs marks arguments of `query` as ones that must
+// never be logged through this Registry.
+func (r *Registry) RegisterSensitiveSQLArgs(query string, positions ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sensitiveSQL == nil {
+		r.sensitiveSQL = map[string]map[int]bool{}
+	}
+
+	// WithSQL reads the positions without the lock held, so they're
+	// replaced rather than added to.
+	sensitive := make(map[int]bool, len(r.sensitiveSQL[query])+len(positions))
+	for p := range r.sensitiveSQL[query] {
+		sensitive[p] = true
+	}
+	for _, p := range positions {
+		sensitive[p] = true
+	}
+	r.sensitiveSQL[query] = sensitive
+}
+
+// WithSQL adds tags describing a database query to the context:
+// `sql_query`, truncated to 256 characters, `sql_args_count`, and
+// `sql_query_hash`, which identifies queries that are too long to tell
+// apart once truncated.
+func WithSQL(ctx context.Context, query string, args []interface{}, opts ...SQLOption) context.Context {
+	return registryFor(ctx).WithSQL(ctx, query, args, opts...)
+}
+
+// WithSQL adds tags describing a database query to the context, through this
+// Registry.
+func (r *Registry) WithSQL(ctx context.Context, query string, args []interface{}, opts ...SQLOption) context.Context {
+	var cfg sqlConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	sum := sha256.Sum256([]byte(query))
+	tags := []Tag{
+		{K: "sql_query", V: truncate(query, maxSQLQuery), Override: true},
+		{K: "sql_args_count", V: len(args), Override: true},
+		{K: "sql_query_hash", V: hex.EncodeToString(sum[:])[:8], Override: true},
+	}
+
+	if cfg.args {
+		r.mu.RLock()
+		sensitive := r.sensitiveSQL[query]
+		r.mu.RUnlock()
+
+		logged := make([]interface{}, len(args))
+		for i, a := range args {
+			if sensitive[i] {
+				a = "[REDACTED]"
+			}
+			logged[i] = a
+		}
+		tags = append(tags, Tag{K: "sql_args", V: logged, Override: true})
+	}
+
+	return r.WithAll(ctx, tags...)
+}
+
+// truncate shortens `s` to at most `n` bytes, without splitting a character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
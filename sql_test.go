@@ -0,0 +1,55 @@
+package ctxlog
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestWithSQLArgs(t *testing.T) {
+	const query = "SELECT * FROM users WHERE email = $1 AND password = $2"
+
+	r := NewRegistry()
+	r.RegisterSensitiveSQLArgs(query, 1)
+
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		want  []interface{}
+	}{
+		{"redacted", query, []interface{}{"a@b.c", "hunter2"}, []interface{}{"a@b.c", "[REDACTED]"}},
+		{"other query", "SELECT 1", []interface{}{"hunter2"}, []interface{}{"hunter2"}},
+		{"no args", query, nil, []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := r.WithSQL(context.Background(), tt.query, tt.args, WithSQLArgs(true))
+
+			got, _ := FirstTagValue(ctx, "sql_args")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sql_args = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterSensitiveSQLArgsConcurrently(t *testing.T) {
+	const query = "SELECT $1, $2"
+
+	r := NewRegistry()
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			r.RegisterSensitiveSQLArgs(query, i%2)
+		}(i)
+		go func() {
+			defer wg.Done()
+			r.WithSQL(context.Background(), query, []interface{}{1, 2}, WithSQLArgs(true))
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,166 @@
+package ctxlog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SinkMetrics describes how much work a sink has done, and how well it went.
+type SinkMetrics struct {
+	EntriesWritten int64
+	BytesWritten   int64
+	ErrorCount     int64
+	EntriesDropped int64
+	LastError      error
+	LastWrite      time.Time
+}
+
+// statsSink is implemented by sinks that keep track of their own SinkMetrics.
+type statsSink interface {
+	Stats() SinkMetrics
+}
+
+// sinkCounters keeps SinkMetrics up to date for a sink.
+type sinkCounters struct {
+	mu      sync.Mutex
+	metrics SinkMetrics
+}
+
+// record counts a single write of `n` bytes that finished with `err`.
+func (sc *sinkCounters) record(n int, err error) {
+	sc.recordBatch(1, n, err)
+}
+
+// recordBatch counts a write of `entries` entries in `n` bytes that finished
+// with `err`.
+func (sc *sinkCounters) recordBatch(entries, n int, err error) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.metrics.LastWrite = time.Now()
+	if err != nil {
+		sc.metrics.ErrorCount++
+		sc.metrics.LastError = err
+		return
+	}
+
+	sc.metrics.EntriesWritten += int64(entries)
+	sc.metrics.BytesWritten += int64(n)
+}
+
+// drop counts `entries` entries which were thrown away without being
+// written, because the sink couldn't keep up.
+func (sc *sinkCounters) drop(entries int) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	sc.metrics.EntriesDropped += int64(entries)
+}
+
+// Stats returns a copy of the current metrics.
+func (sc *sinkCounters) Stats() SinkMetrics {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	return sc.metrics
+}
+
+// SinkStats returns the metrics for each sink that keeps track of them,
+// keyed by the name it was registered under.
+func SinkStats() map[string]SinkMetrics {
+	return std.SinkStats()
+}
+
+// SinkStats returns the metrics for each sink in this Registry that keeps
+// track of them, keyed by the name it was registered under. Route sinks are
+// included, unless a usual sink has the same name.
+func (r *Registry) SinkStats() map[string]SinkMetrics {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ret := map[string]SinkMetrics{}
+	for name, sink := range r.routeSinks {
+		if s, ok := sink.(statsSink); ok {
+			ret[name] = s.Stats()
+		}
+	}
+	for name, sink := range r.sinks {
+		if s, ok := sink.(statsSink); ok {
+			ret[name] = s.Stats()
+		}
+	}
+
+	return ret
+}
+
+// InstrumentedSink keeps SinkMetrics for a sink that doesn't track its own.
+type InstrumentedSink struct {
+	sinkCounters
+
+	name  string
+	inner Sink
+}
+
+// NewInstrumentedSink wraps `inner` so that it shows up in SinkStats. Since
+// the wrapper can't see what the inner sink actually wrote, BytesWritten
+// counts the length of each entry's message.
+func NewInstrumentedSink(name string, inner Sink) *InstrumentedSink {
+	return &InstrumentedSink{name: name, inner: inner}
+}
+
+// Name returns the name the sink was created with.
+func (is *InstrumentedSink) Name() string {
+	return is.name
+}
+
+// attach passes the Registry on to the inner sink.
+func (is *InstrumentedSink) attach(r *Registry) {
+	attach(is.inner, r)
+}
+
+// Log passes the entry to the inner sink and records the result.
+func (is *InstrumentedSink) Log(entry LogEntry) error {
+	return is.LogContext(context.Background(), entry)
+}
+
+// LogContext passes the entry to the inner sink, along with `ctx` if the
+// inner sink takes one, and records the result.
+func (is *InstrumentedSink) LogContext(ctx context.Context, entry LogEntry) error {
+	var err error
+	if sc, ok := is.inner.(SinkWithContext); ok {
+		err = sc.LogContext(ctx, entry)
+	} else {
+		err = is.inner.Log(entry)
+	}
+
+	is.record(len(entry.Message), err)
+	return err
+}
+
+// Level returns the inner sink's level, or LevelDebug if it takes entries at
+// every level.
+func (is *InstrumentedSink) Level() Level {
+	if ls, ok := is.inner.(LeveledSink); ok {
+		return ls.Level()
+	}
+
+	return LevelDebug
+}
+
+// SetLevel sets the inner sink's level, if it has one.
+func (is *InstrumentedSink) SetLevel(level Level) {
+	if ls, ok := is.inner.(LeveledSink); ok {
+		ls.SetLevel(level)
+	}
+}
+
+// Close closes the inner sink, or flushes it if it can't be closed.
+func (is *InstrumentedSink) Close() error {
+	return closeSink(is.inner)
+}
+
+// Flush flushes the inner sink, if it can be flushed.
+func (is *InstrumentedSink) Flush() error {
+	return flushSink(is.inner)
+}
@@ -0,0 +1,82 @@
+package ctxlog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// ctxSink keeps the contexts it's given, and only takes entries at `level`
+// and above.
+type ctxSink struct {
+	recordingSink
+	level Level
+	ctxs  []context.Context
+	err   error
+}
+
+func (cs *ctxSink) LogContext(ctx context.Context, entry LogEntry) error {
+	cs.ctxs = append(cs.ctxs, ctx)
+	if cs.err != nil {
+		return cs.err
+	}
+
+	return cs.recordingSink.Log(entry)
+}
+
+func (cs *ctxSink) Level() Level         { return cs.level }
+func (cs *ctxSink) SetLevel(level Level) { cs.level = level }
+
+func TestInstrumentedSink(t *testing.T) {
+	failure := errors.New("write failed")
+
+	tests := []struct {
+		name      string
+		inner     *ctxSink
+		wantStats SinkMetrics
+	}{
+		{"success", &ctxSink{}, SinkMetrics{EntriesWritten: 2, BytesWritten: 10}},
+		{"failure", &ctxSink{err: failure}, SinkMetrics{ErrorCount: 2, LastError: failure}},
+		{"leveled", &ctxSink{level: LevelWarn}, SinkMetrics{EntriesWritten: 1, BytesWritten: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			r.RemoveSink("console")
+			r.console.out = io.Discard
+			r.UseSink("s", NewInstrumentedSink("s", tt.inner))
+
+			ctx := r.With(context.Background(), "request", 1)
+			r.Infof(ctx, "hello")
+			r.Errorf(ctx, "world")
+
+			got := r.SinkStats()["s"]
+			got.LastWrite = tt.wantStats.LastWrite
+			if got != tt.wantStats {
+				t.Errorf("stats are %+v, want %+v", got, tt.wantStats)
+			}
+			if len(tt.inner.ctxs) == 0 {
+				t.Error("inner sink wasn't given a context")
+			}
+		})
+	}
+}
+
+func TestSinkStatsRouteSinks(t *testing.T) {
+	r := NewRegistry()
+	r.RemoveSink("console")
+	r.UseRouteSink("errors", NewInstrumentedSink("errors", &recordingSink{}))
+	r.RegisterRoute(func(e LogEntry) bool { return e.Level >= LevelError }, "errors")
+
+	r.Infof(context.Background(), "fine")
+	r.Errorf(context.Background(), "broken")
+
+	stats, ok := r.SinkStats()["errors"]
+	if !ok {
+		t.Fatal("route sink is missing from SinkStats")
+	}
+	if stats.EntriesWritten != 1 {
+		t.Errorf("route sink wrote %d entries, want 1", stats.EntriesWritten)
+	}
+}
@@ -0,0 +1,79 @@
+package ctxlog
+
+import (
+	"context"
+	"log"
+	"strings"
+)
+
+// stdWriter receives output from a standard library *log.Logger and sends it
+// through ctxlog instead.
+type stdWriter struct {
+	r     *Registry
+	ctx   context.Context
+	level Level
+
+	// The logger writing to this, whose prefix is taken off each line and
+	// logged as the `logger_prefix` tag instead. Nil for loggers without one.
+	prefixed *log.Logger
+}
+
+// Write emits each line in p as its own log entry. A logger's prefix is only
+// written at the start of p, so it applies to all of them.
+func (w stdWriter) Write(p []byte) (int, error) {
+	if !w.r.enabled(w.level) {
+		return len(p), nil
+	}
+
+	ctx, text := w.ctx, string(p)
+	if w.prefixed != nil {
+		if prefix := w.prefixed.Prefix(); prefix != "" && strings.HasPrefix(text, prefix) {
+			text = strings.TrimPrefix(text, prefix)
+			ctx = w.r.withAll(ctx, Tag{K: "logger_prefix", V: strings.TrimSpace(prefix), Override: true})
+		}
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		w.r.logf(ctx, w.level, "%s", line)
+	}
+
+	return len(p), nil
+}
+
+// NewStdLogger returns a *log.Logger which logs through ctxlog at the given
+// level. The tags in `ctx` at the time of the call are attached to every line,
+// along with `logger_source=stdlib`. Useful for libraries that only accept a
+// *log.Logger.
+func NewStdLogger(ctx context.Context, level Level) *log.Logger {
+	return registryFor(ctx).NewStdLogger(ctx, level)
+}
+
+// NewStdLogger returns a *log.Logger which logs through this Registry at the
+// given level.
+func (r *Registry) NewStdLogger(ctx context.Context, level Level) *log.Logger {
+	ctx = r.withAll(ctx, Tag{K: "logger_source", V: "stdlib"})
+	return log.New(stdWriter{r: r, ctx: ctx, level: level}, "", 0)
+}
+
+// Wrap returns a copy of `l` which logs through ctxlog at the info level with
+// the tags in `ctx`, so that code using a *log.Logger can be moved over
+// without changing its call sites. The logger's prefix, including any set
+// later with SetPrefix, is logged as the `logger_prefix` tag rather than as
+// part of the message.
+func Wrap(l *log.Logger, ctx context.Context) *log.Logger {
+	return registryFor(ctx).Wrap(l, ctx)
+}
+
+// Wrap returns a copy of `l` which logs through this Registry at the info
+// level.
+func (r *Registry) Wrap(l *log.Logger, ctx context.Context) *log.Logger {
+	w := &stdWriter{
+		r:     r,
+		ctx:   r.withAll(ctx, Tag{K: "logger_source", V: "stdlib"}),
+		level: LevelInfo,
+	}
+
+	// Entries have their own timestamps, so the logger's flags are dropped.
+	w.prefixed = log.New(w, l.Prefix(), 0)
+	return w.prefixed
+}
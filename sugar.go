@@ -0,0 +1,76 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+)
+
+// Infow logs `msg` at the info level, with `keysAndValues` as extra tags:
+//
+//	ctxlog.Infow(ctx, "user logged in", "user_id", 123, "method", "password")
+//
+// The tags are only added to this entry, not to `ctx`. Keys that aren't
+// strings are formatted with fmt.Sprint.
+func Infow(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	registryFor(ctx).Infow(ctx, msg, keysAndValues...)
+}
+
+// Debugw logs `msg` at the debug level with extra tags, like Infow.
+func Debugw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	registryFor(ctx).Debugw(ctx, msg, keysAndValues...)
+}
+
+// Warnw logs `msg` as a warning with extra tags, like Infow.
+func Warnw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	registryFor(ctx).Warnw(ctx, msg, keysAndValues...)
+}
+
+// Errorw logs `msg` as an error with extra tags, like Infow.
+func Errorw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	registryFor(ctx).Errorw(ctx, msg, keysAndValues...)
+}
+
+// Infow logs `msg` at the info level with extra tags, through this Registry.
+func (r *Registry) Infow(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	r.Infof(r.withPairs(ctx, keysAndValues), "%s", msg)
+}
+
+// Debugw logs `msg` at the debug level with extra tags, through this
+// Registry.
+func (r *Registry) Debugw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	if !r.enabled(LevelDebug) {
+		return
+	}
+
+	r.Debugf(r.withPairs(ctx, keysAndValues), "%s", msg)
+}
+
+// Warnw logs `msg` as a warning with extra tags, through this Registry.
+func (r *Registry) Warnw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	r.Warnf(r.withPairs(ctx, keysAndValues), "%s", msg)
+}
+
+// Errorw logs `msg` as an error with extra tags, through this Registry.
+func (r *Registry) Errorw(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	r.Errorf(r.withPairs(ctx, keysAndValues), "%s", msg)
+}
+
+// withPairs returns `ctx` with the alternating keys and values in `kv` added
+// as tags. A key without a value is dropped, with a warning.
+func (r *Registry) withPairs(ctx context.Context, kv []interface{}) context.Context {
+	if len(kv)%2 != 0 {
+		r.Warnf(ctx, "odd number of key-value pairs; ignoring key %v", kv[len(kv)-1])
+		kv = kv[:len(kv)-1]
+	}
+
+	tags := make([]Tag, 0, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		k, ok := kv[i].(string)
+		if !ok {
+			k = fmt.Sprint(kv[i])
+		}
+		tags = append(tags, Tag{K: k, V: kv[i+1], Override: true})
+	}
+
+	return r.WithAll(ctx, tags...)
+}
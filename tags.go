@@ -0,0 +1,122 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CopyTag adds all of the values of the tag `key` in `src` to `dst`,
+// replacing any it had before. `dst` is returned as-is if `src` doesn't have
+// the tag.
+func CopyTag(dst, src context.Context, key string) context.Context {
+	return registryFor(dst).CopyTag(dst, src, key)
+}
+
+// MoveTag copies the tag `key` from `src` to `dst` like CopyTag, and also
+// returns `src` without it.
+func MoveTag(dst, src context.Context, key string) (context.Context, context.Context) {
+	return registryFor(dst).MoveTag(dst, src, key)
+}
+
+// CopyTag adds all of the values of the tag `key` in `src` to `dst`,
+// replacing any it had before.
+func (r *Registry) CopyTag(dst, src context.Context, key string) context.Context {
+	vals, ok := tagValues(src, key)
+	if !ok {
+		return dst
+	}
+
+	tags := make([]Tag, len(vals))
+	for i, v := range vals {
+		tags[i] = Tag{K: key, V: v, Override: i == 0}
+	}
+
+	return r.withAll(dst, tags...)
+}
+
+// MoveTag copies the tag `key` from `src` to `dst` like CopyTag, and also
+// returns `src` without it.
+func (r *Registry) MoveTag(dst, src context.Context, key string) (context.Context, context.Context) {
+	if _, ok := tagValues(src, key); !ok {
+		return dst, src
+	}
+
+	return r.CopyTag(dst, src, key), r.without(src, key)
+}
+
+// without returns a copy of `ctx` which doesn't have the tag `key`.
+func (r *Registry) without(ctx context.Context, key string) context.Context {
+	lc, ok := ctx.(LoggingContext)
+	if !ok {
+		return ctx
+	}
+
+	vals, order := lc.tags.clone()
+	delete(vals, key)
+	for i, k := range order {
+		if k == key {
+			order = append(order[:i], order[i+1:]...)
+			break
+		}
+	}
+	lc.tags = newTagSet(vals, order)
+
+	return lc
+}
+
+// Tags returns the tags in `ctx` in the order they were added. Tags with
+// more than one value have all of them as an []interface{}.
+func Tags(ctx context.Context) []Tag {
+	return tagsOf(ctx)
+}
+
+// TagValues returns all of the values of the tag `key` in `ctx`, oldest
+// first, and whether it has the tag at all.
+func TagValues(ctx context.Context, key string) ([]interface{}, bool) {
+	vals, ok := tagValues(ctx, key)
+	if !ok {
+		return nil, false
+	}
+
+	return append([]interface{}(nil), resolve(vals)...), true
+}
+
+// FirstTagValue returns the first value of the tag `key` in `ctx`, and
+// whether it has the tag at all.
+func FirstTagValue(ctx context.Context, key string) (interface{}, bool) {
+	vals, ok := TagValues(ctx, key)
+	if len(vals) == 0 {
+		return nil, false
+	}
+
+	return vals[0], ok
+}
+
+// PrintTags writes the tags in `ctx` to `w`, in the order they were added,
+// like `user_id="123" request_id="abc"`. It's meant for debugging, and
+// prints "(no tags)" if there aren't any.
+func PrintTags(ctx context.Context, w io.Writer) {
+	tags := tagsOf(ctx)
+	if len(tags) == 0 {
+		io.WriteString(w, "(no tags)\n")
+		return
+	}
+
+	parts := make([]string, len(tags))
+	for i, t := range tags {
+		if vals, ok := t.V.([]interface{}); ok {
+			quoted := make([]string, len(vals))
+			for j, v := range vals {
+				quoted[j] = strconv.Quote(fmt.Sprint(v))
+			}
+			parts[i] = fmt.Sprintf("%s=[%s]", t.K, strings.Join(quoted, " "))
+		} else {
+			parts[i] = fmt.Sprintf("%s=%s", t.K, strconv.Quote(fmt.Sprint(t.V)))
+		}
+	}
+
+	io.WriteString(w, strings.Join(parts, " ")+"\n")
+}
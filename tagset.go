@@ -0,0 +1,174 @@
+package ctxlog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// tagSet holds the tags of a LoggingContext. Adding tags doesn't copy the
+// existing ones: the new tagSet just points at the old one, and the full set
+// of tags is only worked out (and then cached) when something reads it. This
+// keeps a chain of With calls linear in the number of tags, rather than
+// quadratic. A nil *tagSet has no tags.
+type tagSet struct {
+	parent *tagSet
+	added  []Tag
+
+	// The tags from the chain, once they've been worked out. They never
+	// change after that, so the sets below this one can build on them.
+	once  sync.Once
+	ready int32
+	vals  map[string][]interface{}
+	order []string
+
+	// Tags set by AppendToTrace after the set was made. They're only seen
+	// through this set, and by sets made from it afterwards, which copy them
+	// into their own `added`; sets that already exist don't see them. cur is
+	// the worked out tags with them applied, or nil if there aren't any.
+	mu       sync.Mutex
+	appended []Tag
+	curVals  map[string][]interface{}
+	curOrder []string
+}
+
+// newTagSet creates a tagSet with the given tags already worked out. It takes
+// ownership of `vals` and `order`.
+func newTagSet(vals map[string][]interface{}, order []string) *tagSet {
+	ts := &tagSet{vals: vals, order: order, ready: 1}
+	ts.once.Do(func() {})
+	return ts
+}
+
+// child returns a set with `tags` added on top of this one, and anything
+// that has been appended to it so far.
+func (ts *tagSet) child(tags []Tag) *tagSet {
+	added := ts.appendedTags()
+	return &tagSet{
+		parent: ts,
+		added:  append(added[:len(added):len(added)], tags...),
+	}
+}
+
+// appendedTags returns the tags set by AppendToTrace so far.
+func (ts *tagSet) appendedTags() []Tag {
+	if ts == nil {
+		return nil
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	return ts.appended
+}
+
+// load returns all of the tags in the set, and the order they were added in.
+// The results must not be changed by the caller.
+func (ts *tagSet) load() (map[string][]interface{}, []string) {
+	if ts == nil {
+		return nil, nil
+	}
+
+	vals, order := ts.base()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.curVals != nil {
+		return ts.curVals, ts.curOrder
+	}
+
+	return vals, order
+}
+
+// base returns the tags from the chain of sets, without any that were
+// appended to this one.
+func (ts *tagSet) base() (map[string][]interface{}, []string) {
+	if ts == nil {
+		return nil, nil
+	}
+
+	ts.once.Do(func() {
+		// Find the closest ancestor that has already been worked out, so that
+		// we only have to apply the tags added since then.
+		var chain []*tagSet
+		base := ts
+		for base != nil && atomic.LoadInt32(&base.ready) == 0 {
+			chain = append(chain, base)
+			base = base.parent
+		}
+
+		vals, order := copyTags(base.base())
+		for i := len(chain) - 1; i >= 0; i-- {
+			for _, x := range chain[i].added {
+				vals, order = applyTag(vals, order, x)
+			}
+		}
+
+		ts.vals, ts.order = vals, order
+		atomic.StoreInt32(&ts.ready, 1)
+	})
+
+	return ts.vals, ts.order
+}
+
+// applyTag adds `x` to `vals` and `order`, which the caller owns.
+func applyTag(vals map[string][]interface{}, order []string, x Tag) (map[string][]interface{}, []string) {
+	// Don't print multiple times.
+	if _, exists := vals[x.K]; !exists {
+		order = append(order, x.K)
+	}
+
+	if x.Override {
+		vals[x.K] = []interface{}{x.V}
+	} else {
+		// The slice is shared with the parent, so make sure that appending
+		// to it copies it.
+		old := vals[x.K]
+		vals[x.K] = append(old[:len(old):len(old)], x.V)
+	}
+
+	return vals, order
+}
+
+// copyTags returns a copy of `vals` and `order` which the caller can change.
+func copyTags(vals map[string][]interface{}, order []string) (map[string][]interface{}, []string) {
+	retVals := make(map[string][]interface{}, len(vals)+1)
+	for k, v := range vals {
+		retVals[k] = v
+	}
+
+	return retVals, append([]string(nil), order...)
+}
+
+// clone returns a copy of the set's tags which the caller can change.
+func (ts *tagSet) clone() (map[string][]interface{}, []string) {
+	return copyTags(ts.load())
+}
+
+// get returns the values of the tag `k`, if the set has it.
+func (ts *tagSet) get(k string) ([]interface{}, bool) {
+	vals, _ := ts.load()
+	v, ok := vals[k]
+	return v, ok
+}
+
+// set replaces the values of the tag `k`, for anything that reads this set
+// afterwards, and for sets made from it afterwards. This is only for
+// AppendToTrace; everything else treats a tagSet as immutable.
+func (ts *tagSet) set(k string, v interface{}) {
+	vals, order := ts.base()
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	// The current tags may have been handed out already, so they're
+	// replaced rather than changed.
+	if ts.curVals != nil {
+		vals, order = ts.curVals, ts.curOrder
+	}
+
+	x := Tag{K: k, V: v, Override: true}
+	cv, co := copyTags(vals, order)
+	ts.curVals, ts.curOrder = applyTag(cv, co, x)
+	ts.appended = append(ts.appended[:len(ts.appended):len(ts.appended)], x)
+}
@@ -0,0 +1,80 @@
+package ctxlog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// keys returns the keys of the tags in `ctx`, in order.
+func keys(ctx context.Context) []string {
+	var ret []string
+	for _, t := range Tags(ctx) {
+		ret = append(ret, t.K)
+	}
+
+	return ret
+}
+
+func TestAppendToTraceExistingChildren(t *testing.T) {
+	r := NewRegistry()
+	parent := r.With(context.Background(), "a", 1)
+	c1 := r.With(parent, "b", 2)
+	c2 := r.With(parent, "b", 2)
+
+	// Reading one child's tags must not change what the append does to it.
+	Tags(c2)
+	r.AppendToTrace(parent, "c", 3)
+	c3 := r.With(parent, "b", 2)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{"parent", parent, []string{"a", "c"}},
+		{"child read before", c2, []string{"a", "b"}},
+		{"child not read before", c1, []string{"a", "b"}},
+		{"child made after", c3, []string{"a", "c", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keys(tt.ctx); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendToTraceWithoutTags(t *testing.T) {
+	r := NewRegistry()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{"WithValue", r.WithValue(context.Background(), "k", "v")},
+		{"Clone", r.Clone(context.Background())},
+		{"withAll", r.withAll(context.Background())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.AppendToTrace(tt.ctx, "c", 3)
+
+			if got := Tags(tt.ctx); len(got) != 1 || got[0].K != "c" || got[0].V != 3 {
+				t.Errorf("Tags = %v, want [c=3]", got)
+			}
+		})
+	}
+}
+
+func TestAppendToTraceReplaces(t *testing.T) {
+	r := NewRegistry()
+	ctx := r.With(r.With(context.Background(), "a", 1), "a", 2)
+	r.AppendToTrace(ctx, "a", 3)
+
+	vals, _ := TagValues(ctx, "a")
+	if !reflect.DeepEqual(vals, []interface{}{3}) {
+		t.Errorf("values = %v, want [3]", vals)
+	}
+}
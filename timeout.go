@@ -0,0 +1,197 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// timeoutReportInterval is how often TimeoutSink reports entries that timed
+// out to the console.
+const timeoutReportInterval = time.Minute
+
+// How many goroutines a TimeoutSink hands entries to the inner sink from,
+// and how many entries can wait for one of them before new ones are dropped.
+const (
+	timeoutWorkers   = 4
+	timeoutQueueSize = 256
+)
+
+// TimeoutSink stops a slow sink from holding up the code that's logging. If
+// the inner sink takes too long with an entry, Log gives up on it and
+// returns an error, leaving the inner sink to finish in the background.
+// Entries are handed to the inner sink by a fixed number of goroutines, so
+// a sink that hangs can't pile them up; once they're all stuck and the
+// queue is full, new entries are dropped and counted in Stats. Close stops
+// the goroutines, once they've handed over what's queued.
+type TimeoutSink struct {
+	sinkCounters
+	sinkReporter
+
+	inner   Sink
+	timeout time.Duration
+
+	start     sync.Once
+	queue     chan timeoutJob
+	workers   sync.WaitGroup
+	stop      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+
+	mu         sync.Mutex
+	timedOut   int64
+	dropped    int64
+	lastReport time.Time
+}
+
+// timeoutJob is an entry waiting to be handed to the inner sink.
+type timeoutJob struct {
+	ctx   context.Context
+	entry LogEntry
+	done  chan error
+}
+
+// NewTimeoutSink creates a sink which waits at most `timeout` for `inner` to
+// log each entry. Entries that time out or are dropped are counted, and the
+// counts are logged to the console every so often.
+func NewTimeoutSink(inner Sink, timeout time.Duration) Sink {
+	return &TimeoutSink{
+		inner:   inner,
+		timeout: timeout,
+		queue:   make(chan timeoutJob, timeoutQueueSize),
+		stop:    make(chan struct{}),
+	}
+}
+
+// attach passes the Registry on to the inner sink, as well as keeping it for
+// reporting timeouts.
+func (ts *TimeoutSink) attach(r *Registry) {
+	ts.sinkReporter.attach(r)
+	attach(ts.inner, r)
+}
+
+// Log hands the entry to the inner sink, and waits for it until the timeout.
+func (ts *TimeoutSink) Log(entry LogEntry) error {
+	return ts.LogContext(context.Background(), entry)
+}
+
+// LogContext hands the entry to the inner sink, and waits for it until the
+// timeout or until `ctx` is done. Inner sinks that take a context are given
+// one that's cancelled when Log gives up on them.
+func (ts *TimeoutSink) LogContext(ctx context.Context, entry LogEntry) error {
+	ts.start.Do(func() {
+		ts.workers.Add(timeoutWorkers)
+		for i := 0; i < timeoutWorkers; i++ {
+			go ts.work()
+		}
+	})
+
+	select {
+	case <-ts.stop:
+		return fmt.Errorf("sink is closed")
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ts.timeout)
+	defer cancel()
+
+	// Buffered, so that the worker can move on even once nobody's waiting.
+	job := timeoutJob{ctx: ctx, entry: entry, done: make(chan error, 1)}
+	select {
+	case ts.queue <- job:
+	default:
+		ts.drop(1)
+		ts.count(&ts.dropped)
+		return fmt.Errorf("sink is too far behind; entry dropped")
+	}
+
+	select {
+	case err := <-job.done:
+		return err
+	case <-ctx.Done():
+		if ctx.Err() != context.DeadlineExceeded {
+			return ctx.Err()
+		}
+
+		ts.count(&ts.timedOut)
+		return fmt.Errorf("sink timed out after %v", ts.timeout)
+	}
+}
+
+// work hands queued entries to the inner sink until the sink is closed, and
+// then hands over whatever is still queued.
+func (ts *TimeoutSink) work() {
+	defer ts.workers.Done()
+
+	for {
+		select {
+		case job := <-ts.queue:
+			ts.handle(job)
+		case <-ts.stop:
+			for {
+				select {
+				case job := <-ts.queue:
+					ts.handle(job)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// handle hands a queued entry to the inner sink, unless it's already been
+// given up on.
+func (ts *TimeoutSink) handle(job timeoutJob) {
+	if err := job.ctx.Err(); err != nil {
+		job.done <- err
+		return
+	}
+
+	if sc, ok := ts.inner.(SinkWithContext); ok {
+		job.done <- sc.LogContext(job.ctx, job.entry)
+	} else {
+		job.done <- ts.inner.Log(job.entry)
+	}
+}
+
+// Close stops the sink's goroutines once they've handed what's queued to the
+// inner sink, and then closes the inner sink, or flushes it if it can't be
+// closed. Entries logged afterwards are rejected.
+func (ts *TimeoutSink) Close() error {
+	ts.closeOnce.Do(func() {
+		// Make sure the goroutines aren't started after this.
+		ts.start.Do(func() {})
+		close(ts.stop)
+		ts.workers.Wait()
+
+		ts.closeErr = closeSink(ts.inner)
+	})
+
+	return ts.closeErr
+}
+
+// count adds an entry to `n`, which is either the timed out or the dropped
+// count, and logs both counts if it's been long enough since the last
+// report.
+func (ts *TimeoutSink) count(n *int64) {
+	ts.mu.Lock()
+	*n++
+	if time.Since(ts.lastReport) < timeoutReportInterval {
+		ts.mu.Unlock()
+		return
+	}
+
+	timedOut, dropped := ts.timedOut, ts.dropped
+	ts.timedOut, ts.dropped = 0, 0
+	ts.lastReport = time.Now()
+	ts.mu.Unlock()
+
+	ts.report("%d log entries timed out after %v, and %d were dropped", timedOut, ts.timeout, dropped)
+}
+
+// Flush flushes the inner sink, if it can be flushed.
+func (ts *TimeoutSink) Flush() error {
+	return flushSink(ts.inner)
+}
@@ -0,0 +1,82 @@
+package ctxlog
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowSink keeps the entries it's given after waiting `delay`, and counts
+// how many times it's closed.
+type slowSink struct {
+	recordingSink
+	delay  time.Duration
+	closed int32
+}
+
+func (ss *slowSink) Log(entry LogEntry) error {
+	time.Sleep(ss.delay)
+	return ss.recordingSink.Log(entry)
+}
+
+func (ss *slowSink) Close() error {
+	atomic.AddInt32(&ss.closed, 1)
+	return nil
+}
+
+func TestTimeoutSink(t *testing.T) {
+	tests := []struct {
+		name      string
+		delay     time.Duration
+		close     bool
+		wantErr   bool
+		wantInner int
+	}{
+		{"fast", 0, false, false, 1},
+		{"slow", 50 * time.Millisecond, false, true, 0},
+		{"closed", 0, true, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &slowSink{delay: tt.delay}
+			ts := NewTimeoutSink(inner, 10*time.Millisecond).(*TimeoutSink)
+			defer ts.Close()
+
+			if tt.close {
+				ts.Close()
+			}
+
+			err := ts.Log(LogEntry{Message: "hello"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Log returned %v, want an error: %v", err, tt.wantErr)
+			}
+			if got := len(inner.Entries()); got != tt.wantInner {
+				t.Errorf("inner sink has %d entries, want %d", got, tt.wantInner)
+			}
+		})
+	}
+}
+
+func TestTimeoutSinkClose(t *testing.T) {
+	inner := &slowSink{delay: 5 * time.Millisecond}
+	ts := NewTimeoutSink(inner, time.Second).(*TimeoutSink)
+
+	for i := 0; i < 3; i++ {
+		if err := ts.Log(LogEntry{Message: "hello"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := ts.Close(); err != nil {
+			t.Errorf("Close returned %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&inner.closed); got != 1 {
+		t.Errorf("inner sink closed %d times, want 1", got)
+	}
+	if got := len(inner.Entries()); got != 3 {
+		t.Errorf("inner sink has %d entries, want 3", got)
+	}
+}
@@ -0,0 +1,92 @@
+package ctxlog
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// netConfig holds the settings shared by every sink that talks over the
+// network.
+type netConfig struct {
+	tls *tls.Config
+
+	// Any error from applying the options, which is returned when the sink
+	// tries to log.
+	err error
+}
+
+// transport returns the http.RoundTripper to use for this configuration.
+func (nc *netConfig) transport() http.RoundTripper {
+	if nc.tls == nil {
+		return http.DefaultTransport
+	}
+
+	return nc.pooledTransport(0)
+}
+
+// pooledTransport returns a new transport for this configuration, which
+// keeps up to `idle` connections per host open for reuse. Zero uses the
+// default.
+func (nc *netConfig) pooledTransport(idle int) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = nc.tls
+	if idle > 0 {
+		t.MaxIdleConnsPerHost = idle
+	}
+
+	return t
+}
+
+// SinkOption configures any of the sinks that talk over the network.
+type SinkOption func(*netConfig)
+
+// applyHTTP lets a SinkOption be passed to NewHTTPSink.
+func (o SinkOption) applyHTTP(hs *HTTPSink) {
+	o(&hs.net)
+}
+
+// applyVector lets a SinkOption be passed to NewVectorSink.
+func (o SinkOption) applyVector(vs *VectorSink) {
+	o(&vs.net)
+}
+
+// WithTLSConfig uses `cfg` for connections made by the sink.
+func WithTLSConfig(cfg *tls.Config) SinkOption {
+	return func(nc *netConfig) {
+		nc.tls = cfg
+	}
+}
+
+// WithClientCertificate authenticates the sink's connections with the client
+// certificate in `certFile` and `keyFile`, for servers that require mutual
+// TLS. If `caFile` is set, the server must present a certificate signed by
+// one of the CAs in it; otherwise the system's certificate pool is used.
+func WithClientCertificate(certFile, keyFile, caFile string) SinkOption {
+	return func(nc *netConfig) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			nc.err = fmt.Errorf("could not load client certificate: %v", err)
+			return
+		}
+
+		cfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+		if caFile != "" {
+			pem, err := os.ReadFile(caFile)
+			if err != nil {
+				nc.err = fmt.Errorf("could not read CA certificates: %v", err)
+				return
+			}
+
+			cfg.RootCAs = x509.NewCertPool()
+			if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+				nc.err = fmt.Errorf("no CA certificates found in %s", caFile)
+				return
+			}
+		}
+
+		nc.tls = cfg
+	}
+}
@@ -0,0 +1,320 @@
+package ctxlog
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StartTrace starts a span named `name`, for code that can't be wrapped in a
+// callback for Trace. It returns the context for the span, and a function
+// which ends it; the span is logged as an error if that's given one.
+//
+//	ctx, finish := ctxlog.StartTrace(ctx, "fetchUser")
+//	defer func() { finish(err) }()
+func StartTrace(ctx context.Context, name string) (context.Context, func(error)) {
+	return registryFor(ctx).StartTrace(ctx, name)
+}
+
+// TraceVerbose is like Trace, but logs `span_start` when the span starts as
+// well as `span_end` when it ends, both at the info level. That's twice as
+// many entries, but spans can be followed while they're still running.
+func TraceVerbose(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return registryFor(ctx).TraceVerbose(ctx, name, fn)
+}
+
+// TraceNoError is like Trace, but always logs the span at the info level,
+// without any error tags, for operations where errors are expected and
+// handled.
+func TraceNoError(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return registryFor(ctx).TraceNoError(ctx, name, fn)
+}
+
+// TraceCtx is like Trace, but for callbacks that return a context, like
+// middleware that adds to it. The context returned by `fn` is passed back to
+// the caller, so that what `fn` added to it isn't lost.
+func TraceCtx(ctx context.Context, name string, fn func(ctx context.Context) (context.Context, error)) (context.Context, error) {
+	return registryFor(ctx).TraceCtx(ctx, name, fn)
+}
+
+// TraceWith is like Trace, but adds `attrs` to the span's context before
+// calling `fn`, so they're on the span and everything logged inside it.
+// `fn` can still replace them.
+func TraceWith(ctx context.Context, name string, attrs []Tag, fn func(ctx context.Context) error) error {
+	return registryFor(ctx).TraceWith(ctx, name, attrs, fn)
+}
+
+// spanMode is how a span is logged.
+type spanMode int
+
+const (
+	// Log the end of the span, as an error with error tags if it failed.
+	spanDefault spanMode = iota
+	// Log the start and end of the span, always at the info level.
+	spanVerbose
+	// Log the end of the span at the info level, ignoring any error.
+	spanNoError
+)
+
+// Trace allows nested logging of operations. Each span gets a `span_id`, and
+// spans that aren't inside another one start a `trace_id` that's shared by
+// everything inside them. If `fn` returns an error, the span is logged as an
+// error, with `error_flag=true`, `error_msg` and `error_type` tags. The
+// `error` tag is left to WithError, so it's always the error string.
+func (r *Registry) Trace(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return r.trace(ctx, name, spanDefault, fn)
+}
+
+// TraceVerbose is like Trace, but logs the start of the span as well as the
+// end.
+func (r *Registry) TraceVerbose(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return r.trace(ctx, name, spanVerbose, fn)
+}
+
+// TraceNoError is like Trace, but always logs the span at the info level.
+func (r *Registry) TraceNoError(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return r.trace(ctx, name, spanNoError, fn)
+}
+
+// TraceWith is like Trace, but adds `attrs` to the span's context before
+// calling `fn`.
+func (r *Registry) TraceWith(ctx context.Context, name string, attrs []Tag, fn func(ctx context.Context) error) error {
+	return r.trace(ctx, name, spanDefault, fn, attrs...)
+}
+
+// TraceCtx is like Trace, but passes back the context returned by `fn`.
+func (r *Registry) TraceCtx(ctx context.Context, name string, fn func(ctx context.Context) (context.Context, error)) (context.Context, error) {
+	spanCtx, finish, err := r.startTrace(ctx, name, spanDefault)
+	if err != nil {
+		return ctx, err
+	}
+
+	ret, err := fn(spanCtx)
+	finish(err)
+	return ret, err
+}
+
+func (r *Registry) trace(ctx context.Context, name string, mode spanMode, fn func(ctx context.Context) error, attrs ...Tag) error {
+	ctx, finish, err := r.startTrace(ctx, name, mode, attrs...)
+	if err != nil {
+		return err
+	}
+
+	err = fn(ctx)
+	finish(err)
+	return err
+}
+
+// StartTrace starts a span named `name`, for code that can't be wrapped in a
+// callback for Trace. It returns the context for the span, and a function
+// which ends it; the span is logged as an error if that's given one.
+func (r *Registry) StartTrace(ctx context.Context, name string) (context.Context, func(error)) {
+	spanCtx, finish, err := r.startTrace(ctx, name, spanDefault)
+	if err != nil {
+		return ctx, func(error) {}
+	}
+
+	return spanCtx, finish
+}
+
+// startTrace starts a span with the given attributes, and returns the
+// function that ends it.
+func (r *Registry) startTrace(ctx context.Context, name string, mode spanMode, attrs ...Tag) (context.Context, func(error), error) {
+	switch ctx.(type) {
+	case LoggingContext:
+		c := ctx.(LoggingContext)
+
+		if n, ok := c.tags.get("span_id"); ok {
+			ctx = r.withAll(ctx, Tag{
+				K:        "parent_id",
+				V:        n[0],
+				Override: true,
+			})
+		}
+	default:
+	}
+
+	if debugChecks && ctx.Err() != nil {
+		r.Warnf(ctx, "span %q started with a context that is already done: %v", name, ctx.Err())
+	}
+
+	// Keeping track of parents and names costs a hash and a tag on every
+	// span, so only do it while debugging.
+	release := func() {}
+	if debugChecks || r.enabled(LevelDebug) {
+		release = r.trackParent(ctx, name)
+		ctx = r.checkSpanName(ctx, name)
+	}
+
+	spanID, err := uuid.NewRandom()
+	if err != nil {
+		release()
+		r.Errorf(ctx, "could not generate span ID: %v", err)
+		return nil, nil, err
+	}
+
+	// Root spans start a trace, which the spans inside them carry on.
+	if _, ok := tagValues(ctx, "trace_id"); !ok {
+		traceID, err := uuid.NewRandom()
+		if err != nil {
+			release()
+			r.Errorf(ctx, "could not generate trace ID: %v", err)
+			return nil, nil, err
+		}
+		ctx = r.withAll(ctx, Tag{K: "trace_id", V: traceID.String(), Override: true})
+	}
+
+	start := time.Now()
+	ctx = r.withAll(ctx,
+		Tag{
+			K:        "span_id",
+			V:        spanID.String(),
+			Override: true,
+		},
+		Tag{
+			K:        "name",
+			V:        name,
+			Override: true,
+		},
+		Tag{
+			K:        "span_depth",
+			V:        spanDepth(ctx) + 1,
+			Override: true,
+		},
+	)
+	ctx = r.WithAll(ctx, attrs...)
+	ctx, state := withSpanState(ctx)
+	if mode == spanVerbose {
+		r.Infof(r.withAll(ctx, Tag{K: "start_time", V: start.Unix(), Override: true}), "span_start")
+	}
+
+	finish := func(err error) {
+		end := time.Now()
+		release()
+		ctx := r.withAll(ctx, state.tags()...)
+		ctx = r.withAll(ctx,
+			r.durationTag(end.Sub(start)),
+			Tag{
+				K:        "end_time",
+				V:        end.Unix(),
+				Override: true,
+			},
+			Tag{
+				K:        "start_time",
+				V:        start.Unix(),
+				Override: true,
+			},
+		)
+
+		if err != nil && mode != spanNoError {
+			ctx = r.withAll(ctx,
+				Tag{K: "error_flag", V: true, Override: true},
+				Tag{K: "error_msg", V: err.Error(), Override: true},
+				Tag{K: "error_type", V: reflect.TypeOf(err).String(), Override: true},
+			)
+		}
+
+		switch {
+		case mode == spanVerbose:
+			r.Infof(ctx, "span_end")
+		case err != nil && mode != spanNoError:
+			r.Errorf(ctx, "span")
+		default:
+			r.Infof(ctx, "span")
+		}
+	}
+
+	return ctx, finish, nil
+}
+
+// spanDepth returns how many spans `ctx` is nested in.
+func spanDepth(ctx context.Context) int {
+	vals, ok := tagValues(ctx, "span_depth")
+	if !ok || len(vals) == 0 {
+		return 0
+	}
+
+	depth, _ := depthValue(vals[len(vals)-1])
+	return depth
+}
+
+// depthValue reads a `span_depth` value, which is a float64 if it's been
+// through JSON, like in an entry read back from a log file.
+func depthValue(v interface{}) (int, bool) {
+	switch d := v.(type) {
+	case int:
+		return d, true
+	case float64:
+		return int(d), true
+	default:
+		return 0, false
+	}
+}
+
+// checkSpanName warns if a span enclosing the one being started in `ctx`
+// has the same name, since their entries would be hard to tell apart. The
+// names of enclosing spans are kept in the `span_names` tag.
+func (r *Registry) checkSpanName(ctx context.Context, name string) context.Context {
+	names, _ := tagValues(ctx, "span_names")
+	for _, n := range names {
+		if n == name {
+			r.Warnf(ctx, "span name %q is already used by an enclosing span", name)
+			break
+		}
+	}
+
+	return r.withAll(ctx, Tag{K: "span_names", V: name})
+}
+
+// durationUnits are the units that span durations can be logged in, and
+// the tag each is logged as.
+var durationUnits = map[time.Duration]string{
+	time.Nanosecond:  "dur_ns",
+	time.Microsecond: "dur_us",
+	time.Millisecond: "dur_ms",
+	time.Second:      "dur_s",
+}
+
+// SetTraceDurationUnit sets the unit that span durations are logged in, for
+// operations too quick to be measured in milliseconds. The tag is named
+// after the unit: `dur_ns`, `dur_us`, `dur_ms` (the default) or `dur_s`.
+// Other units are ignored.
+func SetTraceDurationUnit(unit time.Duration) {
+	std.SetTraceDurationUnit(unit)
+}
+
+// SetTraceDurationUnit sets the unit that span durations are logged in
+// through this Registry.
+func (r *Registry) SetTraceDurationUnit(unit time.Duration) {
+	if _, ok := durationUnits[unit]; !ok {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.durUnit = unit
+}
+
+// durationTag returns the tag for a span that took `d`.
+func (r *Registry) durationTag(d time.Duration) Tag {
+	r.mu.RLock()
+	unit := r.durUnit
+	r.mu.RUnlock()
+
+	return Tag{K: durationUnits[unit], V: int64(d / unit), Override: true}
+}
+
+// WithSpanID sets the context's span ID to one that came from outside, like
+// an upstream service, without starting a new span.
+func WithSpanID(ctx context.Context, spanID string) context.Context {
+	return registryFor(ctx).withAll(ctx, Tag{K: "span_id", V: spanID, Override: true})
+}
+
+// WithTraceID sets the context's trace ID to one that came from outside, like
+// a load balancer or API gateway.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return registryFor(ctx).withAll(ctx, Tag{K: "trace_id", V: traceID, Override: true})
+}
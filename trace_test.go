@@ -0,0 +1,96 @@
+package ctxlog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// recordingSink keeps the entries it's given.
+type recordingSink struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+func (rs *recordingSink) Log(entry LogEntry) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.entries = append(rs.entries, entry)
+	return nil
+}
+
+func (rs *recordingSink) Entries() []LogEntry {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return append([]LogEntry(nil), rs.entries...)
+}
+
+// newRecordingRegistry creates a Registry that logs everything to the
+// returned sink, and nothing to the console.
+func newRecordingRegistry(opts ...RegistryOption) (*Registry, *recordingSink) {
+	r := NewRegistry(append([]RegistryOption{WithDebug(true)}, opts...)...)
+	r.RemoveSink("console")
+
+	sink := &recordingSink{}
+	r.UseSink("record", sink)
+	return r, sink
+}
+
+func TestTrace(t *testing.T) {
+	failure := errors.New("it broke")
+
+	tests := []struct {
+		name      string
+		trace     func(r *Registry, ctx context.Context, name string, fn func(context.Context) error) error
+		err       error
+		messages  []string
+		level     Level
+		wantError bool
+	}{
+		{"success", (*Registry).Trace, nil, []string{"span"}, LevelInfo, false},
+		{"failure", (*Registry).Trace, failure, []string{"span"}, LevelError, true},
+		{"verbose", (*Registry).TraceVerbose, nil, []string{"span_start", "span_end"}, LevelInfo, false},
+		{"no error", (*Registry).TraceNoError, failure, []string{"span"}, LevelInfo, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, sink := newRecordingRegistry()
+			ctx := r.WithError(context.Background(), failure)
+
+			err := tt.trace(r, ctx, "op", func(context.Context) error { return tt.err })
+			if err != tt.err {
+				t.Errorf("Trace returned %v, want %v", err, tt.err)
+			}
+
+			entries := sink.Entries()
+			if len(entries) != len(tt.messages) {
+				t.Fatalf("logged %d entries, want %d", len(entries), len(tt.messages))
+			}
+			for i, e := range entries {
+				if e.Message != tt.messages[i] {
+					t.Errorf("entry %d is %q, want %q", i, e.Message, tt.messages[i])
+				}
+			}
+
+			end := entries[len(entries)-1]
+			if end.Level != tt.level {
+				t.Errorf("span logged at %v, want %v", end.Level, tt.level)
+			}
+			if v, _ := end.tag("name"); v != "op" {
+				t.Errorf("span has name %v, want %q", v, "op")
+			}
+			if _, ok := end.tag("span_id"); !ok {
+				t.Error("span has no span_id")
+			}
+			if v, _ := end.tag("error"); v != failure.Error() {
+				t.Errorf("span has error %v, want the WithError string", v)
+			}
+			if _, ok := end.tag("error_flag"); ok != tt.wantError {
+				t.Errorf("span has error_flag: %v, want %v", ok, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package ctxlog
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// TraceFunc wraps `fn` so that each call to it is a span named `name`, like
+// Trace. `fn` can be any function whose first argument is a
+// context.Context and whose last result is an error; the span is given the
+// context, and logged as an error if the function returns one. The result
+// has the same type as `fn`, so it can be asserted back:
+//
+//	getUser := ctxlog.TraceFunc("getUser", db.GetUser).(func(context.Context, int) (*User, error))
+//
+// TraceFunc panics if `fn` is nil or doesn't have that shape. Calls through the
+// wrapper are slower than direct ones, since they go through reflection.
+func TraceFunc(name string, fn interface{}) interface{} {
+	v := reflect.ValueOf(fn)
+	if !v.IsValid() || (v.Kind() == reflect.Func && v.IsNil()) {
+		panic(fmt.Sprintf("ctxlog: TraceFunc(%q) was given a nil function", name))
+	}
+
+	t := v.Type()
+	if t.Kind() != reflect.Func || t.NumIn() == 0 || t.In(0) != contextType ||
+		t.NumOut() == 0 || t.Out(t.NumOut()-1) != errorType {
+		panic(fmt.Sprintf("ctxlog: TraceFunc needs a func(context.Context, ...) (..., error), got %s", t))
+	}
+
+	call := v.Call
+	if t.IsVariadic() {
+		call = v.CallSlice
+	}
+
+	return reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+		ctx, _ := args[0].Interface().(context.Context)
+		if ctx == nil {
+			return call(args)
+		}
+
+		spanCtx, finish, err := registryFor(ctx).startTrace(ctx, name, spanDefault)
+		if err != nil {
+			return call(args)
+		}
+
+		args[0] = reflect.ValueOf(&spanCtx).Elem()
+		results := call(args)
+
+		err, _ = results[len(results)-1].Interface().(error)
+		finish(err)
+		return results
+	}).Interface()
+}
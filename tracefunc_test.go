@@ -0,0 +1,75 @@
+package ctxlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTraceFuncPanics(t *testing.T) {
+	var nilFunc func(context.Context) error
+
+	tests := []struct {
+		name string
+		fn   interface{}
+		want string
+	}{
+		{"nil", nil, "nil function"},
+		{"nil func", nilFunc, "nil function"},
+		{"not a func", 42, "needs a func"},
+		{"no context", func() error { return nil }, "needs a func"},
+		{"no error", func(context.Context) {}, "needs a func"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				p := recover()
+				if p == nil || !strings.Contains(fmt.Sprint(p), tt.want) {
+					t.Errorf("TraceFunc panicked with %v, want %q", p, tt.want)
+				}
+			}()
+
+			TraceFunc("op", tt.fn)
+		})
+	}
+}
+
+func TestTraceFunc(t *testing.T) {
+	failure := errors.New("it broke")
+
+	tests := []struct {
+		name      string
+		err       error
+		wantLevel Level
+	}{
+		{"success", nil, LevelInfo},
+		{"failure", failure, LevelError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, sink := newRecordingRegistry()
+			ctx := ContextWithLogger(context.Background(), r)
+
+			var inSpan bool
+			fn := TraceFunc("op", func(ctx context.Context, n int) (int, error) {
+				_, inSpan = tagValues(ctx, "span_id")
+				return n * 2, tt.err
+			}).(func(context.Context, int) (int, error))
+
+			n, err := fn(ctx, 21)
+			if n != 42 || err != tt.err {
+				t.Errorf("wrapper returned %d, %v; want 42, %v", n, err, tt.err)
+			}
+			if !inSpan {
+				t.Error("function wasn't called inside a span")
+			}
+
+			entries := sink.Entries()
+			if len(entries) != 1 || entries[0].Level != tt.wantLevel {
+				t.Errorf("logged %v, want one span at %v", entries, tt.wantLevel)
+			}
+		})
+	}
+}
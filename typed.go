@@ -0,0 +1,31 @@
+package ctxlog
+
+import (
+	"context"
+)
+
+// WithTyped sets the tag `key` to `value`, replacing any values it already
+// had. Unlike With, the value can be read back with its type intact by
+// GetTyped.
+func WithTyped[T any](ctx context.Context, key string, value T) context.Context {
+	// Not normalized, since that could change the value's type.
+	return registryFor(ctx).withNamespaced(ctx, []Tag{{K: key, V: value, Override: true}})
+}
+
+// GetTyped returns the latest value of the tag `key` in `ctx`, if it has one
+// and it is a T. Like WithTyped, it looks in the context's namespace.
+func GetTyped[T any](ctx context.Context, key string) (T, bool) {
+	var zero T
+
+	vals, ok := tagValues(ctx, namespaced(ctx, key))
+	if !ok || len(vals) == 0 {
+		return zero, false
+	}
+
+	v, ok := vals[len(vals)-1].(T)
+	if !ok {
+		return zero, false
+	}
+
+	return v, true
+}
@@ -0,0 +1,41 @@
+package ctxlog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxTagKeyLen is the longest tag key that ValidateTagKey allows.
+const maxTagKeyLen = 64
+
+// ValidateTagKey checks that `k` can be used as a tag key in every output
+// format: it must not be empty or longer than 64 bytes, and must not contain
+// `=`, `"`, newlines or NUL bytes.
+func ValidateTagKey(k string) error {
+	if k == "" {
+		return fmt.Errorf("tag key is empty")
+	}
+
+	if len(k) > maxTagKeyLen {
+		return fmt.Errorf("tag key %q is longer than %d bytes", k, maxTagKeyLen)
+	}
+
+	if i := strings.IndexAny(k, "=\"\n\r\x00"); i >= 0 {
+		return fmt.Errorf("tag key %q contains %q", k, k[i])
+	}
+
+	return nil
+}
+
+// validateTags returns a `tag_key_error` tag for each tag with an invalid
+// key, so that they show up in the logs.
+func validateTags(tags []Tag) []Tag {
+	var errs []Tag
+	for _, t := range tags {
+		if err := ValidateTagKey(t.K); err != nil {
+			errs = append(errs, Tag{K: "tag_key_error", V: err.Error()})
+		}
+	}
+
+	return errs
+}
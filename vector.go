@@ -0,0 +1,262 @@
+package ctxlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// VectorSink sends entries in batches to the HTTP source of a Vector
+// (https://vector.dev) pipeline, as newline-delimited JSON.
+type VectorSink struct {
+	sinkCounters
+	sinkReporter
+
+	net    netConfig
+	url    string
+	client *http.Client
+
+	batchSize     int
+	flushInterval time.Duration
+	maxRetries    int
+	backoff       time.Duration
+
+	mu      sync.Mutex
+	pending []LogEntry
+
+	// Full batches waiting for the background flusher to send them.
+	batches chan []LogEntry
+
+	stop      chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// VectorOption configures a VectorSink. Any SinkOption can be used as one.
+type VectorOption interface {
+	applyVector(*VectorSink)
+}
+
+// vectorOption is a VectorOption that only applies to Vector sinks.
+type vectorOption func(*VectorSink)
+
+func (o vectorOption) applyVector(vs *VectorSink) {
+	o(vs)
+}
+
+// WithBatchSize sets how many entries are sent to Vector at once. The
+// default is 100.
+func WithBatchSize(n int) VectorOption {
+	return vectorOption(func(vs *VectorSink) {
+		if n > 0 {
+			vs.batchSize = n
+		}
+	})
+}
+
+// WithFlushInterval sets how often entries are sent even if there aren't a
+// full batch of them. The default is a second.
+func WithFlushInterval(d time.Duration) VectorOption {
+	return vectorOption(func(vs *VectorSink) {
+		if d > 0 {
+			vs.flushInterval = d
+		}
+	})
+}
+
+// WithRetries sets how many times sending a batch is retried, and how long
+// to wait before the first retry. The wait doubles after each one. The
+// default is 3 retries, starting at 100ms.
+func WithRetries(n int, backoff time.Duration) VectorOption {
+	return vectorOption(func(vs *VectorSink) {
+		vs.maxRetries = n
+		vs.backoff = backoff
+	})
+}
+
+// vectorQueuedBatches is how many full batches can be waiting to be sent
+// before new ones are dropped.
+const vectorQueuedBatches = 4
+
+// NewVectorSink creates a sink which sends entries to the Vector HTTP source
+// at `url`. Call Close to send any remaining entries before exiting.
+func NewVectorSink(url string, opts ...VectorOption) *VectorSink {
+	vs := &VectorSink{
+		url:           url,
+		batchSize:     100,
+		flushInterval: time.Second,
+		maxRetries:    3,
+		backoff:       100 * time.Millisecond,
+		batches:       make(chan []LogEntry, vectorQueuedBatches),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt.applyVector(vs)
+	}
+
+	vs.client = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: vs.net.pooledTransport(16),
+	}
+
+	go vs.flushPeriodically()
+	return vs
+}
+
+// Log queues the entry, and hands the queue to the background flusher if
+// it's a full batch. It never waits for Vector: if the flusher is too far
+// behind to take the batch, it's dropped and counted in Stats.
+func (vs *VectorSink) Log(entry LogEntry) error {
+	vs.mu.Lock()
+	vs.pending = append(vs.pending, entry)
+	var batch []LogEntry
+	if len(vs.pending) >= vs.batchSize {
+		batch, vs.pending = vs.pending, nil
+	}
+	vs.mu.Unlock()
+
+	if batch == nil {
+		return nil
+	}
+
+	select {
+	case vs.batches <- batch:
+	default:
+		vs.drop(len(batch))
+	}
+
+	return nil
+}
+
+// Flush sends any queued entries to Vector, waiting until they've been sent.
+func (vs *VectorSink) Flush() error {
+	var errs multiError
+	for drained := false; !drained; {
+		select {
+		case batch := <-vs.batches:
+			if err := vs.sendBatch(batch); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			drained = true
+		}
+	}
+
+	vs.mu.Lock()
+	batch := vs.pending
+	vs.pending = nil
+	vs.mu.Unlock()
+
+	if err := vs.sendBatch(batch); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs.errOrNil()
+}
+
+// sendBatch encodes `batch` and sends it to Vector.
+func (vs *VectorSink) sendBatch(batch []LogEntry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, e := range batch {
+		// Vector wants the time of the event in `timestamp`.
+		doc := e.ToJSONFlat()
+		delete(doc, "time")
+		doc["timestamp"] = e.Time.Format(time.RFC3339Nano)
+
+		if err := enc.Encode(doc); err != nil {
+			vs.recordBatch(len(batch), 0, err)
+			return err
+		}
+	}
+
+	err := vs.send(body.Bytes())
+	vs.recordBatch(len(batch), body.Len(), err)
+	return err
+}
+
+// Close stops the background flushing, and sends any queued entries.
+func (vs *VectorSink) Close() error {
+	vs.closeOnce.Do(func() { close(vs.stop) })
+	<-vs.done
+
+	return vs.Flush()
+}
+
+func (vs *VectorSink) flushPeriodically() {
+	defer close(vs.done)
+
+	ticker := time.NewTicker(vs.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case <-vs.stop:
+			return
+		case batch := <-vs.batches:
+			err = vs.sendBatch(batch)
+		case <-ticker.C:
+			err = vs.Flush()
+		}
+
+		if err != nil {
+			vs.report("Could not send entries to Vector: %v", err)
+		}
+	}
+}
+
+// send posts `body`, retrying with exponential backoff if it fails in a way
+// that might work next time.
+func (vs *VectorSink) send(body []byte) error {
+	if vs.net.err != nil {
+		return vs.net.err
+	}
+
+	wait := vs.backoff
+	var err error
+	for attempt := 0; attempt <= vs.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(wait)
+			wait *= 2
+		}
+
+		var retry bool
+		if retry, err = vs.post(body); err == nil || !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// post sends `body` once, and reports whether it's worth retrying if it
+// failed.
+func (vs *VectorSink) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, vs.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := vs.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("unexpected response from %s: %s", vs.url, resp.Status)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,71 @@
+package ctxlog
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newVectorServer starts a server that counts the lines Vector would get.
+func newVectorServer(t *testing.T) (*httptest.Server, *int64) {
+	var lines int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		scanner := bufio.NewScanner(req.Body)
+		for scanner.Scan() {
+			atomic.AddInt64(&lines, 1)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &lines
+}
+
+func TestVectorSink(t *testing.T) {
+	tests := []struct {
+		name      string
+		batchSize int
+		entries   int
+	}{
+		{"partial batch", 10, 3},
+		{"full batch", 5, 5},
+		{"several batches", 4, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, lines := newVectorServer(t)
+			vs := NewVectorSink(srv.URL, WithBatchSize(tt.batchSize), WithFlushInterval(time.Hour))
+
+			for i := 0; i < tt.entries; i++ {
+				if err := vs.Log(LogEntry{Message: "hello"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := vs.Close(); err != nil {
+				t.Fatalf("Close returned %v", err)
+			}
+
+			if got := atomic.LoadInt64(lines); got != int64(tt.entries) {
+				t.Errorf("Vector got %d entries, want %d", got, tt.entries)
+			}
+		})
+	}
+}
+
+func TestVectorSinkCloseConcurrently(t *testing.T) {
+	srv, _ := newVectorServer(t)
+	vs := NewVectorSink(srv.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vs.Close()
+		}()
+	}
+	wg.Wait()
+}